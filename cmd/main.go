@@ -48,7 +48,7 @@ func main() {
 	// creates the clientset
 	clientset := kubernetes.NewForConfigOrDie(config)
 	ctx = k8sapi.WithK8sInterface(ctx, clientset)
-	ambAgent := agent.NewAgent(ctx, nil, agent.NewArgoRolloutsGetter, agent.NewSecretsGetter, env)
+	ambAgent := agent.NewAgent(ctx, nil, agent.NewArgoRolloutsGetter, agent.NewSecretsGetter, agent.NewDynamicInterface, agent.NewDeploymentsGetter, agent.NewConfigMapsGetter, agent.NewPodsGetter, env)
 
 	ambAgent.SetReportDiagnosticsAllowed(env.AESReportDiagnostics)
 
@@ -2,25 +2,32 @@ package agent
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 
 	// load all auth plugins.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
@@ -44,10 +51,13 @@ const (
 type Comm interface {
 	Close() error
 	Report(context.Context, *agent.Snapshot, string) error
+	ReportDelta(context.Context, *agent.SnapshotDelta, string) error
 	ReportCommandResult(context.Context, *agent.CommandResult, string) error
 	Directives() <-chan *agent.Directive
 	StreamMetrics(context.Context, *agent.StreamMetricsMessage, string) error
 	StreamDiagnostics(context.Context, *agent.Diagnostics, string) error
+	StreamLogs(context.Context, string, func(func(*agent.LogsChunk) error) error) error
+	State() ConnState
 }
 
 // Agent is the component that talks to the DCP Director, which is a cloud
@@ -55,7 +65,10 @@ type Comm interface {
 type Agent struct {
 	rpc.UnsafeAgentServer
 	*Env
-	comm             Comm
+	comm Comm
+	// dialBackoff and nextDial pace the attempts to create comm
+	dialBackoff      backoff
+	nextDial         time.Time
 	agentID          *agent.Identity
 	newDirective     <-chan *agent.Directive
 	directiveHandler DirectiveHandler
@@ -64,14 +77,33 @@ type Agent struct {
 	ambassadorAPIKeyEnvVarValue string
 
 	// State managed by the director via the retriever
-	reportingStopped bool // Did the director say don't report?
-	lastDirectiveID  string
+	reportingStopped     bool // Did the director say don't report?
+	lastDirectiveID      string
+	acceptedContentTypes []string // Content types the director can decode
 
 	// The state of reporting
 	reportToSend   *agent.Snapshot // Report that's ready to send
 	reportRunning  atomic.Bool     // Is a report being sent right now?
 	reportComplete chan error      // Report() finished with this error
 
+	// snapshotDeltas tracks what the Director has seen so that only changes
+	// need to be reported. Full snapshots are always sent when nil.
+	snapshotDeltas *snapshotDeltaTracker
+
+	// spool keeps the reports that could not be sent until the Director can
+	// be reached again. Nothing is spooled when nil.
+	spool *reportSpool
+
+	// commandResults remembers the results of executed commands, so that a
+	// command delivered again is not executed again. Nothing is remembered when nil.
+	commandResults *commandResultCache
+
+	// commandQueue runs commands in the background, and the results of those
+	// commands come back through completedCommands. Commands run right away
+	// when nil.
+	commandQueue      *commandQueue
+	completedCommands chan *agent.CommandResult
+
 	// apiDocsStore holds OpenAPI documents from cluster Mappings
 	apiDocsStore *APIDocsStore
 
@@ -80,6 +112,10 @@ type Agent struct {
 	rolloutStore *RolloutStore
 	// applicationStore holds Argo Applications state from cluster
 	applicationStore *ApplicationStore
+	// gatewayAPI holds Gateway API state from cluster
+	gatewayAPI *gatewayAPIStore
+	// extraResources holds the state of the resources configured in AGENT_EXTRA_RESOURCES
+	extraResources *extraResourcesStore
 
 	// Extra headers to inject into RPC requests to ambassador cloud.
 	rpcExtraHeaders []string
@@ -93,12 +129,17 @@ type Agent struct {
 	diagnosticsReportRunning  atomic.Bool // Is a report being sent right now?
 	diagnosticsReportComplete chan error  // Report() finished with this error
 
+	// The state of metrics reporting
+	metricsReportPeriod  time.Duration // Set by the director; metrics are not reported when zero
+	metricsReportRunning atomic.Bool   // Is a metrics report being sent right now?
+
 	// Stand-alone config
 	emissaryPresent bool   // if not installed by emissary, generate snapshots
 	clusterId       string // cluster id used in generated snapshots
 	clusterDomain   string // the cluster domain name, e.g. .cluster.local
 
 	// snapshot watchers
+	exclusions      *watchers.Exclusions // the resources that are not reported; nil excludes kube-system only
 	coreWatchers    watchers.SnapshotWatcher
 	fallbackWatcher watchers.SnapshotWatcher
 	// config watchers
@@ -106,7 +147,7 @@ type Agent struct {
 	ambassadorWatcher *AmbassadorWatcher
 
 	currentSnapshotMutex sync.Mutex
-	currentSnapshot      *snapshotTypes.Snapshot
+	currentSnapshot      *watchers.Snapshot
 }
 
 // NewAgent returns a new Agent.
@@ -115,14 +156,33 @@ func NewAgent(
 	directiveHandler DirectiveHandler,
 	rolloutsGetterFactory rolloutsGetterFactory,
 	secretsGetterFactory secretsGetterFactory,
+	resourceClientFactory resourceClientFactory,
+	deploymentsGetterFactory deploymentsGetterFactory,
+	configMapsGetterFactory configMapsGetterFactory,
+	podsGetterFactory podsGetterFactory,
 	env *Env,
 ) *Agent {
 	if directiveHandler == nil {
-		directiveHandler = &BasicDirectiveHandler{
-			DefaultMinReportPeriod: defaultMinReportPeriod,
-			rolloutsGetterFactory:  rolloutsGetterFactory,
-			secretsGetterFactory:   secretsGetterFactory,
+		dh := &BasicDirectiveHandler{
+			DefaultMinReportPeriod:   defaultMinReportPeriod,
+			rolloutsGetterFactory:    rolloutsGetterFactory,
+			secretsGetterFactory:     secretsGetterFactory,
+			resourceClientFactory:    resourceClientFactory,
+			deploymentsGetterFactory: deploymentsGetterFactory,
+			configMapsGetterFactory:  configMapsGetterFactory,
+			podsGetterFactory:        podsGetterFactory,
+		}
+		if env.CommandPolicyFile != "" {
+			dh.policy = newCommandPolicyFile(env.CommandPolicyFile)
 		}
+		if env.AuditLog {
+			var events typedcorev1.EventsGetter
+			if env.AuditEvents {
+				events = k8sapi.GetK8sInterface(ctx).CoreV1()
+			}
+			dh.audit = newAuditSink(os.Stdout, events)
+		}
+		directiveHandler = dh
 	}
 
 	rpcExtraHeaders := make([]string, 0)
@@ -135,22 +195,57 @@ func NewAgent(
 		)
 	}
 
+	var spool *reportSpool
+	if env.SpoolDir != "" {
+		var err error
+		if spool, err = newReportSpool(env.SpoolDir, env.SpoolMaxCommandResults); err != nil {
+			dlog.Errorf(ctx, "Unable to use spool directory %s, reports that fail to send will be lost: %v", env.SpoolDir, err)
+		}
+	}
+
+	var commandResults *commandResultCache
+	if env.CommandCacheSize > 0 {
+		commandResults = newCommandResultCache(env.CommandCacheSize)
+		if env.CommandCacheConfigMap != "" {
+			configMaps := k8sapi.GetK8sInterface(ctx).CoreV1().ConfigMaps(env.AgentNamespace)
+			if err := commandResults.persistTo(ctx, configMaps, env.CommandCacheConfigMap); err != nil {
+				dlog.Errorf(ctx, "Unable to load command results from configmap %s: %v", env.CommandCacheConfigMap, err)
+			}
+		}
+	}
+
+	var queue *commandQueue
+	if env.CommandWorkers > 0 {
+		queue = newCommandQueue(env.CommandWorkers)
+	}
+
+	exclusions := watchers.NewExclusions(ctx, env.NamespacesToWatch, env.ExcludeSelector.Selector, env.ExcludeNamespaceSelector.Selector)
+
 	clusterDomain := getClusterDomain(ctx, env)
 	dlog.Infof(ctx, "Using cluster domain %q", clusterDomain)
 
 	return &Agent{
 		Env:            env,
 		reportComplete: make(chan error),
+		snapshotDeltas: newSnapshotDeltaTracker(env.FullResyncPeriod),
+		spool:          spool,
+		commandResults: commandResults,
+
+		commandQueue:      queue,
+		completedCommands: make(chan *agent.CommandResult),
 
 		ambassadorAPIKeyEnvVarValue: env.AmbassadorAPIKey,
 		directiveHandler:            directiveHandler,
 		rpcExtraHeaders:             rpcExtraHeaders,
 
 		// k8sapi watchers
-		coreWatchers:      watchers.NewCoreWatchers(ctx, env.NamespacesToWatch, objectModifier),
+		exclusions:        exclusions,
+		coreWatchers:      watchers.NewCoreWatchers(ctx, env.NamespacesToWatch, objectModifier, exclusions),
 		configWatchers:    NewConfigWatchers(ctx, env.AgentNamespace),
 		ambassadorWatcher: NewAmbassadorWatcher(ctx, env.AgentNamespace),
-		fallbackWatcher:   watchers.NewFallbackWatcher(ctx, env.NamespacesToWatch, objectModifier),
+		fallbackWatcher:   watchers.NewFallbackWatcher(ctx, env.NamespacesToWatch, objectModifier, exclusions),
+		gatewayAPI:        &gatewayAPIStore{},
+		extraResources:    newExtraResourcesStore(env.ExtraResources),
 		clusterDomain:     clusterDomain,
 	}
 }
@@ -207,6 +302,28 @@ func (a *Agent) SetMinReportPeriod(ctx context.Context, dur time.Duration) {
 	a.MinReportPeriod = dur
 }
 
+// RequestFullResync makes the agent send a full snapshot with its next report.
+func (a *Agent) RequestFullResync(ctx context.Context) {
+	dlog.Debug(ctx, "full snapshot resync requested")
+	if a.snapshotDeltas != nil {
+		a.snapshotDeltas.RequestFullResync()
+	}
+}
+
+// SetAcceptedContentTypes sets the content types that the Director is able to
+// decode, in order of preference. Reports are compressed accordingly.
+func (a *Agent) SetAcceptedContentTypes(ctx context.Context, contentTypes []string) {
+	dlog.Debugf(ctx, "accepted content types %v -> %v", a.acceptedContentTypes, contentTypes)
+	a.acceptedContentTypes = contentTypes
+}
+
+// SetMetricsReportPeriod sets how often Envoy metrics are streamed to the
+// Director. Zero stops metrics reporting.
+func (a *Agent) SetMetricsReportPeriod(ctx context.Context, dur time.Duration) {
+	dlog.Debugf(ctx, "metrics report period %s -> %s", a.metricsReportPeriod, dur)
+	a.metricsReportPeriod = dur
+}
+
 func (a *Agent) SetLastDirectiveID(ctx context.Context, id string) {
 	dlog.Debugf(ctx, "setting last directive ID %s", id)
 	a.lastDirectiveID = id
@@ -324,11 +441,14 @@ func (a *Agent) Watch(ctx context.Context) error {
 	dlog.Info(ctx, "Agent is running...")
 	configCh := k8sapi.Subscribe(ctx, a.configWatchers.cond)
 	a.waitForAPIKey(ctx, configCh)
+	a.exclusions.EnsureStarted(ctx)
 	a.coreWatchers.EnsureStarted(ctx)
 	a.handleAmbassadorEndpointChange(ctx, a.AESSnapshotURL.Hostname())
 	ambCh := k8sapi.Subscribe(ctx, a.ambassadorWatcher.cond)
 
 	go a.argoWatch(ctx)
+	go a.gatewayAPIWatch(ctx)
+	go a.extraResourcesWatch(ctx)
 	return a.watch(ctx, configCh, ambCh)
 }
 
@@ -509,6 +629,8 @@ func (a *Agent) watch( //nolint:gocognit,cyclop // TODO: Refactor this function
 			a.handleAmbassadorEndpointChange(ctx, a.AESSnapshotURL.Hostname())
 		case directive := <-a.newDirective:
 			a.directiveHandler.HandleDirective(ctx, a, directive)
+		case result := <-a.completedCommands:
+			a.commandCompleted(ctx, result)
 		}
 
 		// only ask ambassador for a snapshot if we're actually going to report it.
@@ -551,18 +673,45 @@ func (a *Agent) watch( //nolint:gocognit,cyclop // TODO: Refactor this function
 			// The communications channel to the DCP was not yet created or was
 			// closed above, due to a change in identity, or close elsewhere, due to
 			// a change in endpoint configuration.
-			newComm, err := NewComm(
-				ctx, a.ConnAddress, a.agentID, a.AmbassadorAPIKey, a.rpcExtraHeaders)
+			if time.Now().Before(a.nextDial) {
+				continue
+			}
+			var (
+				tlsConfig *tls.Config
+				newComm   *RPCComm
+				err       error
+			)
+			if a.ConnAddress.secure {
+				tlsConfig, err = a.directorTLSConfig()
+			}
+			if err == nil {
+				newComm, err = NewComm(
+					ctx, a.ConnAddress, tlsConfig, a.RPCProxyURL, a.agentID, a.AmbassadorAPIKey, a.rpcExtraHeaders)
+			}
 			if err != nil {
+				delay := a.dialBackoff.Next()
+				a.nextDial = time.Now().Add(delay)
 				dlog.Warnf(ctx, "Failed to dial the DCP: %v", err)
-				dlog.Warn(ctx, "DCP functionality disabled until next retry")
+				dlog.Warnf(ctx, "DCP functionality disabled, retrying in %s", delay)
 				continue
 			}
+			a.dialBackoff.Reset()
 
 			a.comm = newComm
 			a.newDirective = a.comm.Directives()
 		}
 
+		if a.comm.State() == ConnStateAuthFailed {
+			// Don't hammer the Director with a token that it rejected. The comm
+			// gives the token another chance once its backoff has elapsed.
+			dlog.Tracef(ctx, "Director rejected CLOUD_CONNECT_TOKEN, not reporting")
+			continue
+		}
+
+		if a.spool != nil {
+			a.replaySpool(ctx)
+		}
+
 		if !a.reportingStopped && !a.reportRunning.Load() && a.reportToSend != nil {
 			a.ReportSnapshot(ctx)
 		} else {
@@ -588,6 +737,14 @@ func (a *Agent) watch( //nolint:gocognit,cyclop // TODO: Refactor this function
 			// reports
 			dlog.Tracef(ctx, "Not reporting diagnostics [reporting stopped = %t] [report running = %t]", a.diagnosticsReportingStopped, a.diagnosticsReportRunning.Load())
 		}
+
+		if a.metricsReportPeriod > 0 && !a.metricsReportRunning.Load() {
+			a.ReportMetrics(ctx, a.AESMetricsURL)
+		} else {
+			// Don't report if the Director didn't ask for metrics, or if we are
+			// already sending a report or waiting for the metrics report period
+			dlog.Tracef(ctx, "Not reporting metrics [metrics report period = %s] [report running = %t]", a.metricsReportPeriod, a.metricsReportRunning.Load())
+		}
 	}
 }
 
@@ -617,11 +774,14 @@ func (a *Agent) ReportSnapshot(ctx context.Context) {
 	// Send a report. This is an RPC, i.e. it can block, so we do this in a
 	// goroutine. Sleep after send, so we don't need to keep track of
 	// whether/when it's okay to send the next report.
-	go func(ctx context.Context, report *agent.Snapshot, delay time.Duration, apikey string) {
-		err := a.comm.Report(ctx, report, apikey)
+	go func(ctx context.Context, report *agent.Snapshot, encoding contentEncoding, delay time.Duration, apikey string) {
+		err := a.sendReport(ctx, report, encoding, apikey)
 		if err != nil {
 			dlog.Warnf(ctx, "failed to report: %+v", err)
 		}
+		if a.spool != nil {
+			a.spoolReport(ctx, report, err)
+		}
 		dlog.Debugf(ctx, "Finished sending snapshot report, sleeping for %s", delay.String())
 		time.Sleep(delay)
 		a.reportRunning.Store(false)
@@ -633,12 +793,85 @@ func (a *Agent) ReportSnapshot(ctx context.Context) {
 		default:
 			// do nothing if nobody is listening
 		}
-	}(ctx, a.reportToSend, a.MinReportPeriod, a.AmbassadorAPIKey)
+	}(ctx, a.reportToSend, negotiateContentEncoding(a.reportToSend.ContentType, a.acceptedContentTypes), a.MinReportPeriod, a.AmbassadorAPIKey)
 
 	// Update state variables
 	a.reportToSend = nil // Set when a snapshot yields a fresh report
 }
 
+// sendReport sends the changes since the last accepted report to the Director,
+// or the full report when the Director needs a full resync or doesn't support
+// deltas. Full reports are compressed with the given encoding.
+func (a *Agent) sendReport(ctx context.Context, report *agent.Snapshot, encoding contentEncoding, apiKey string) error {
+	if a.snapshotDeltas == nil {
+		return a.sendFullReport(ctx, report, encoding, apiKey)
+	}
+
+	idx, delta, err := a.snapshotDeltas.Prepare(report)
+	if err != nil {
+		dlog.Warnf(ctx, "unable to compute snapshot delta, sending full snapshot: %v", err)
+		return a.sendFullReport(ctx, report, encoding, apiKey)
+	}
+
+	if delta != nil {
+		err = a.sendDelta(ctx, delta, encoding, apiKey)
+		if status.Code(err) != codes.Unimplemented {
+			if err == nil {
+				a.snapshotDeltas.Accept(idx, false)
+			}
+			return err
+		}
+		dlog.Info(ctx, "Director does not support snapshot deltas, will send full snapshots")
+		a.snapshotDeltas.Disable()
+	}
+
+	if err = a.sendFullReport(ctx, report, encoding, apiKey); err == nil {
+		a.snapshotDeltas.Accept(idx, true)
+	}
+	return err
+}
+
+// sendFullReport sends the full report to the Director, compressing the raw
+// snapshot with the given encoding. The report itself is left untouched.
+func (a *Agent) sendFullReport(ctx context.Context, report *agent.Snapshot, encoding contentEncoding, apiKey string) error {
+	if encoding != contentEncodingNone {
+		raw, err := encodeContent(encoding, report.RawSnapshot)
+		if err != nil {
+			return err
+		}
+		report = proto.Clone(report).(*agent.Snapshot)
+		report.RawSnapshot = raw
+		report.ContentType = encodedContentType(report.ContentType, encoding)
+	}
+	return a.comm.Report(ctx, report, apiKey)
+}
+
+// sendDelta sends the delta to the Director, compressing its raw remainder and
+// raw objects with the given encoding. The ones that are empty stay empty, and
+// the delta itself is left untouched.
+func (a *Agent) sendDelta(ctx context.Context, delta *agent.SnapshotDelta, encoding contentEncoding, apiKey string) error {
+	if encoding != contentEncodingNone {
+		encode := func(data []byte) ([]byte, error) {
+			if len(data) == 0 {
+				return data, nil
+			}
+			return encodeContent(encoding, data)
+		}
+		delta = proto.Clone(delta).(*agent.SnapshotDelta)
+		var err error
+		if delta.RawRemainder, err = encode(delta.RawRemainder); err != nil {
+			return err
+		}
+		for _, od := range delta.Deltas {
+			if od.RawObject, err = encode(od.RawObject); err != nil {
+				return err
+			}
+		}
+		delta.ContentType = encodedContentType(delta.ContentType, encoding)
+	}
+	return a.comm.ReportDelta(ctx, delta, apiKey)
+}
+
 // ReportDiagnostics ...
 func (a *Agent) ReportDiagnostics(ctx context.Context, diagnosticsURL *url.URL) {
 	// TODO maybe put request in go-routine
@@ -661,8 +894,8 @@ func (a *Agent) ReportDiagnostics(ctx context.Context, diagnosticsURL *url.URL)
 	// Send a diagnostics report. This is an RPC, i.e. it can block, so we do this in a
 	// goroutine. Sleep after send, so we don't need to keep track of
 	// whether/when it's okay to send the next report.
-	go func(ctx context.Context, diagnosticsReport *agent.Diagnostics, delay time.Duration, apikey string) {
-		err := a.comm.StreamDiagnostics(ctx, diagnosticsReport, apikey)
+	go func(ctx context.Context, diagnosticsReport *agent.Diagnostics, encoding contentEncoding, delay time.Duration, apikey string) {
+		err := a.sendDiagnostics(ctx, diagnosticsReport, encoding, apikey)
 		if err != nil {
 			dlog.Warnf(ctx, "failed to do diagnostics report: %+v", err)
 		}
@@ -677,7 +910,23 @@ func (a *Agent) ReportDiagnostics(ctx context.Context, diagnosticsURL *url.URL)
 		default:
 			// do nothing if nobody is listening
 		}
-	}(ctx, agentDiagnostics, a.MinReportPeriod, a.AmbassadorAPIKey) // minReportPeriod is the one set for snapshots
+	}(ctx, agentDiagnostics, negotiateContentEncoding(agentDiagnostics.ContentType, a.acceptedContentTypes),
+		a.MinReportPeriod, a.AmbassadorAPIKey) // minReportPeriod is the one set for snapshots
+}
+
+// sendDiagnostics sends the diagnostics report to the Director, compressing the
+// raw diagnostics with the given encoding.
+func (a *Agent) sendDiagnostics(ctx context.Context, diagnostics *agent.Diagnostics, encoding contentEncoding, apiKey string) error {
+	if encoding != contentEncodingNone {
+		raw, err := encodeContent(encoding, diagnostics.RawDiagnostics)
+		if err != nil {
+			return err
+		}
+		diagnostics = proto.Clone(diagnostics).(*agent.Diagnostics)
+		diagnostics.RawDiagnostics = raw
+		diagnostics.ContentType = encodedContentType(diagnostics.ContentType, encoding)
+	}
+	return a.comm.StreamDiagnostics(ctx, diagnostics, apiKey)
 }
 
 // ProcessSnapshot turns a Watt/Diag Snapshot into a report that the agent can
@@ -697,24 +946,37 @@ func (a *Agent) ProcessSnapshot(ctx context.Context, snapshot *snapshotTypes.Sna
 	}
 	a.agentID = agentID
 
+	// Better skip the snapshot than report the resources of excluded namespaces
+	if err := a.exclusions.Check(ctx); err != nil {
+		return fmt.Errorf("unable to find the excluded namespaces: %w", err)
+	}
+
+	// The reported snapshot also holds the resources that Emissary doesn't watch
+	extended := watchers.NewSnapshot(snapshot)
 	if snapshot.Kubernetes != nil {
 		// load services before pods so that we can do labelMatching
 		if !a.emissaryPresent && a.fallbackWatcher != nil {
-			a.fallbackWatcher.LoadSnapshot(ctx, snapshot)
+			a.fallbackWatcher.LoadSnapshot(ctx, extended)
 		}
 		if a.coreWatchers != nil {
-			a.coreWatchers.LoadSnapshot(ctx, snapshot)
+			a.coreWatchers.LoadSnapshot(ctx, extended)
 		}
 		a.argoLock.Lock()
 		if a.rolloutStore != nil {
-			snapshot.Kubernetes.ArgoRollouts = a.rolloutStore.StateOfWorld()
+			snapshot.Kubernetes.ArgoRollouts = watchers.Filter(ctx, a.exclusions, a.rolloutStore.StateOfWorld())
 			dlog.Debugf(ctx, "Found %d argo rollouts", len(snapshot.Kubernetes.ArgoRollouts))
 		}
 		if a.applicationStore != nil {
-			snapshot.Kubernetes.ArgoApplications = a.applicationStore.StateOfWorld()
+			snapshot.Kubernetes.ArgoApplications = watchers.Filter(ctx, a.exclusions, a.applicationStore.StateOfWorld())
 			dlog.Debugf(ctx, "Found %d argo applications", len(snapshot.Kubernetes.ArgoApplications))
 		}
 		a.argoLock.Unlock()
+		if a.gatewayAPI != nil {
+			a.gatewayAPI.LoadSnapshot(ctx, extended, a.exclusions)
+		}
+		if a.extraResources != nil {
+			a.extraResources.LoadSnapshot(ctx, extended, a.exclusions)
+		}
 		if a.apiDocsStore != nil {
 			a.apiDocsStore.ProcessSnapshot(ctx, snapshot)
 			snapshot.APIDocs = a.apiDocsStore.StateOfWorld()
@@ -727,10 +989,11 @@ func (a *Agent) ProcessSnapshot(ctx context.Context, snapshot *snapshotTypes.Sna
 		return err
 	}
 	a.currentSnapshotMutex.Lock()
-	a.currentSnapshot = snapshot
+	a.currentSnapshot = extended
 	a.currentSnapshotMutex.Unlock()
 
-	rawJsonSnapshot, err := json.Marshal(snapshot)
+	// Only the reported snapshot is redacted, the ingresses are resolved from the current one
+	rawJsonSnapshot, err := json.Marshal(redactSnapshot(ctx, extended, a.SnapshotRedact))
 	if err != nil {
 		dlog.Errorf(ctx, "Error marshalling snapshot: %v", err)
 		return err
@@ -848,6 +1111,42 @@ func objectModifier(obj runtime.Object) {
 		obj.TypeMeta.APIVersion = obj.APIVersion
 		obj.TypeMeta.Kind = obj.Kind
 
+		obj.ObjectMeta.ManagedFields = nil
+	case *appsv1.StatefulSet:
+		obj.Kind = "StatefulSet"
+		obj.APIVersion = "apps/v1"
+		obj.ManagedFields = nil
+
+		obj.TypeMeta.APIVersion = obj.APIVersion
+		obj.TypeMeta.Kind = obj.Kind
+
+		obj.ObjectMeta.ManagedFields = nil
+	case *appsv1.DaemonSet:
+		obj.Kind = "DaemonSet"
+		obj.APIVersion = "apps/v1"
+		obj.ManagedFields = nil
+
+		obj.TypeMeta.APIVersion = obj.APIVersion
+		obj.TypeMeta.Kind = obj.Kind
+
+		obj.ObjectMeta.ManagedFields = nil
+	case *batchv1.Job:
+		obj.Kind = "Job"
+		obj.APIVersion = "batch/v1"
+		obj.ManagedFields = nil
+
+		obj.TypeMeta.APIVersion = obj.APIVersion
+		obj.TypeMeta.Kind = obj.Kind
+
+		obj.ObjectMeta.ManagedFields = nil
+	case *batchv1.CronJob:
+		obj.Kind = "CronJob"
+		obj.APIVersion = "batch/v1"
+		obj.ManagedFields = nil
+
+		obj.TypeMeta.APIVersion = obj.APIVersion
+		obj.TypeMeta.Kind = obj.Kind
+
 		obj.ObjectMeta.ManagedFields = nil
 	case *k8s_resource_types.Ingress:
 		obj.Kind = "Ingress"
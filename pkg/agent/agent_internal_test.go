@@ -16,9 +16,13 @@ import (
 
 	"google.golang.org/protobuf/types/known/durationpb"
 
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 
+	"github.com/datawire/ambassador-agent/pkg/agent/watchers"
 	"github.com/datawire/ambassador-agent/pkg/api/agent"
 	"github.com/datawire/dlib/dlog"
 	diagnosticsTypes "github.com/emissary-ingress/emissary/v3/pkg/diagnostics/v1"
@@ -195,6 +199,46 @@ func TestProcessSnapshot(t *testing.T) {
 	}
 }
 
+func TestProcessSnapshot_Exclusions(t *testing.T) {
+	te := newTestEnv("AGENT_EXCLUDE_SELECTOR", "ambassador-agent/ignore=true")
+	env, err := LoadEnv(te.lookup)
+	require.NoError(t, err)
+	assert.Nil(t, env.ExcludeNamespaceSelector.Selector)
+
+	ctx := dlog.NewTestContext(t, false)
+	rollout := func(name, namespace string, labels map[string]string) *kates.Unstructured {
+		u := &kates.Unstructured{}
+		u.SetUID(types.UID(name))
+		u.SetName(name)
+		u.SetNamespace(namespace)
+		u.SetLabels(labels)
+		return u
+	}
+	rolloutStore, err := NewRolloutStore().FromCallback(&GenericCallback{
+		EventType: CallbackEventAdded,
+		Obj:       rollout("quote", "default", nil),
+		Sotw: []interface{}{
+			rollout("quote", "default", nil),
+			rollout("ignored", "default", map[string]string{"ambassador-agent/ignore": "true"}),
+			rollout("system", "kube-system", nil),
+		},
+	})
+	require.NoError(t, err)
+
+	a := &Agent{
+		Env:          env,
+		exclusions:   watchers.NewExclusions(ctx, nil, env.ExcludeSelector.Selector, env.ExcludeNamespaceSelector.Selector),
+		rolloutStore: rolloutStore,
+	}
+	snapshot := &snapshotTypes.Snapshot{
+		AmbassadorMeta: &snapshotTypes.AmbassadorMetaInfo{ClusterID: "exclusions"},
+		Kubernetes:     &snapshotTypes.KubernetesSnapshot{},
+	}
+	require.NoError(t, a.ProcessSnapshot(ctx, snapshot))
+	require.Len(t, snapshot.Kubernetes.ArgoRollouts, 1)
+	assert.Equal(t, "quote", snapshot.Kubernetes.ArgoRollouts[0].GetName())
+}
+
 func TestProcessDiagnosticsSnapshot(t *testing.T) {
 	t.Parallel()
 	diagnosticsTests := []struct {
@@ -319,6 +363,20 @@ func parseURL(t *testing.T, urlStr string) *url.URL {
 	return u
 }
 
+// newTestAgent returns an Agent that talks to the Director through client.
+func newTestAgent(client *MockClient) *Agent {
+	return &Agent{
+		Env: &Env{},
+		comm: &RPCComm{
+			conn:       client,
+			client:     client,
+			rptWake:    make(chan struct{}, 1),
+			retCancel:  func() {},
+			directives: make(chan *agent.Directive, 1),
+		},
+	}
+}
+
 // Set up a watch and send a MinReportPeriod directive to the directive channel
 // Make sure that Agent.MinReportPeriod is set to this new value.
 func TestWatchReportPeriodDirective(t *testing.T) {
@@ -631,6 +689,8 @@ type MockCoreWatchers struct {
 	endpts []*kates.Endpoints
 	deploy []*kates.Deployment
 	cmaps  []*kates.ConfigMap
+	sts    []*appsv1.StatefulSet
+	jobs   []*batchv1.Job
 	ch     <-chan struct{}
 }
 
@@ -640,11 +700,13 @@ func (m *MockCoreWatchers) Subscribe(ctx context.Context) <-chan struct{} {
 	return m.ch
 }
 
-func (m *MockCoreWatchers) LoadSnapshot(ctx context.Context, snapshot *snapshotTypes.Snapshot) {
+func (m *MockCoreWatchers) LoadSnapshot(ctx context.Context, snapshot *watchers.Snapshot) {
 	snapshot.Kubernetes.Pods = m.pods
 	snapshot.Kubernetes.Endpoints = m.endpts
 	snapshot.Kubernetes.Deployments = m.deploy
 	snapshot.Kubernetes.ConfigMaps = m.cmaps
+	snapshot.Kubernetes.StatefulSets = m.sts
+	snapshot.Kubernetes.Jobs = m.jobs
 }
 
 // Start a watch. Setup a mock client to capture what we would have sent to the agent com
@@ -806,6 +868,30 @@ func TestWatchWithSnapshot(t *testing.T) {
 				},
 			},
 		},
+		sts: []*appsv1.StatefulSet{
+			{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "StatefulSet",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "some-statefulset",
+					Namespace: "default",
+				},
+			},
+		},
+		jobs: []*batchv1.Job{
+			{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "Job",
+					APIVersion: "batch/v1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "some-job",
+					Namespace: "default",
+				},
+			},
+		},
 	}
 
 	// start the watch
@@ -888,6 +974,17 @@ func TestWatchWithSnapshot(t *testing.T) {
 	assert.Equal(t, len(actualSnapshot.Kubernetes.ConfigMaps), 1)
 	assert.Equal(t, len(actualSnapshot.Kubernetes.Deployments), 1)
 
+	// and so do the ones that only the agent reports
+	var extendedSnapshot watchers.Snapshot
+	err = json.Unmarshal(sentSnapshot.RawSnapshot, &extendedSnapshot)
+	assert.Nil(t, err)
+	assert.NotNil(t, extendedSnapshot.Kubernetes)
+	assert.Equal(t, len(extendedSnapshot.Kubernetes.StatefulSets), 1)
+	assert.Equal(t, "some-statefulset", extendedSnapshot.Kubernetes.StatefulSets[0].Name)
+	assert.Equal(t, len(extendedSnapshot.Kubernetes.Jobs), 1)
+	assert.Empty(t, extendedSnapshot.Kubernetes.DaemonSets)
+	assert.Equal(t, len(extendedSnapshot.Kubernetes.Pods), 1)
+
 	/////// Make sure that the timestamp we sent makes sense
 	assert.NotNil(t, sentSnapshot.SnapshotTs)
 	snapshotTime := sentSnapshot.SnapshotTs.AsTime()
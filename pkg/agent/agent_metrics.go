@@ -0,0 +1,15 @@
+package agent
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// agentMetrics holds the Prometheus metrics about the agent itself, which
+// Service serves on /metrics.
+var agentMetrics = prometheus.NewRegistry() //nolint:gochecknoglobals // metrics are process-wide
+
+var commandsDenied = promauto.With(agentMetrics).NewCounterVec(prometheus.CounterOpts{ //nolint:gochecknoglobals // metrics are process-wide
+	Name: "ambassador_agent_commands_denied_total",
+	Help: "Number of commands that the command policy did not allow to run.",
+}, []string{"command"})
@@ -0,0 +1,167 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// applicationAction indicates the action to be performed on an Argo CD Application.
+type applicationAction string
+
+const (
+	// applicationActionSync represents the "sync" action on an Application.
+	applicationActionSync = applicationAction("SYNC")
+	// applicationActionHardRefresh represents the "get --hard-refresh" action on an Application.
+	applicationActionHardRefresh = applicationAction("HARD_REFRESH")
+	// applicationActionTerminateOperation represents the "terminate-op" action on an Application.
+	applicationActionTerminateOperation = applicationAction("TERMINATE_OPERATION")
+	// applicationActionRollback represents the "rollback" action on an Application.
+	applicationActionRollback = applicationAction("ROLLBACK")
+)
+
+const (
+	// argoCDRefreshAnnotation makes Argo CD refresh an Application.
+	argoCDRefreshAnnotation = "argocd.argoproj.io/refresh"
+	// operationInitiator is the user name that operations started by the agent are attributed to.
+	operationInitiator = "ambassador-agent"
+)
+
+// applicationCommand holds a reference to an Application command to be ran.
+type applicationCommand struct {
+	namespace       string
+	applicationName string
+	action          applicationAction
+	revision        string // SYNC only
+	prune           bool   // SYNC and ROLLBACK only
+	historyID       int64  // ROLLBACK only
+	dryRun          bool
+}
+
+func (a *applicationCommand) String() string {
+	return fmt.Sprintf("<application=%s namespace=%s action=%s>", a.applicationName, a.namespace, a.action)
+}
+
+// DryRun tells whether the command is only validated by the API server, without changing anything.
+func (a *applicationCommand) DryRun() bool {
+	return a.dryRun
+}
+
+// RunWithClientFactory runs the given Application command using resourceClientFactory to get a dynamic client.
+func (a *applicationCommand) RunWithClientFactory(ctx context.Context, resourceClientFactory resourceClientFactory) error {
+	client, err := resourceClientFactory()
+	if err != nil {
+		return err
+	}
+	applicationGVR := schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "applications"}
+	return a.patchApplication(ctx, client.Resource(applicationGVR).Namespace(a.namespace))
+}
+
+// patchApplication changes the Application the way the Argo CD API server does, i.e. mostly by
+// setting its operation field, which the Argo CD application controller then carries out.
+func (a *applicationCommand) patchApplication(ctx context.Context, client dynamic.ResourceInterface) error {
+	app, err := client.Get(ctx, a.applicationName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get application %s (%s): %w", a.applicationName, a.namespace, err)
+	}
+	_, operationInProgress := app.Object["operation"]
+
+	var patch map[string]interface{}
+	switch a.action {
+	case applicationActionSync:
+		if operationInProgress {
+			return errors.New("another operation is already in progress")
+		}
+		sync := a.syncOperation()
+		if a.revision != "" {
+			sync["revision"] = a.revision
+		}
+		patch = map[string]interface{}{"operation": newApplicationOperation(sync)}
+	case applicationActionHardRefresh:
+		patch = map[string]interface{}{"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{argoCDRefreshAnnotation: "hard"},
+		}}
+	case applicationActionTerminateOperation:
+		if _, found, _ := unstructured.NestedMap(app.Object, "status", "operationState"); !operationInProgress || !found {
+			return errors.New("unable to terminate operation, no operation is in progress")
+		}
+		patch = map[string]interface{}{"status": map[string]interface{}{
+			"operationState": map[string]interface{}{"phase": "Terminating"},
+		}}
+	case applicationActionRollback:
+		if _, automated, _ := unstructured.NestedMap(app.Object, "spec", "syncPolicy", "automated"); automated {
+			return errors.New("rollback cannot be initiated when auto-sync is enabled")
+		}
+		if operationInProgress {
+			return errors.New("another operation is already in progress")
+		}
+		entry, err := a.historyEntry(app)
+		if err != nil {
+			return err
+		}
+		sync := a.syncOperation()
+		for _, field := range []string{"revision", "revisions", "source", "sources"} {
+			if value, ok := entry[field]; ok {
+				sync[field] = value
+			}
+		}
+		patch = map[string]interface{}{"operation": newApplicationOperation(sync)}
+	default:
+		return fmt.Errorf(
+			"tried to perform unknown action '%s' on application %s (%s)",
+			a.action,
+			a.applicationName,
+			a.namespace,
+		)
+	}
+
+	// Fail rather than act on an Application that changed since we looked at it
+	if err = unstructured.SetNestedField(patch, app.GetResourceVersion(), "metadata", "resourceVersion"); err != nil {
+		return err
+	}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	_, err = client.Patch(ctx, a.applicationName, types.MergePatchType, data, metav1.PatchOptions{DryRun: dryRunOption(a.dryRun)})
+	if err != nil {
+		return fmt.Errorf("failed to %s application %s (%s): %w", a.action, a.applicationName, a.namespace, err)
+	}
+	return nil
+}
+
+func (a *applicationCommand) syncOperation() map[string]interface{} {
+	return map[string]interface{}{
+		"prune":        a.prune,
+		"syncStrategy": map[string]interface{}{"hook": map[string]interface{}{}},
+	}
+}
+
+// historyEntry returns the entry of the Application's deployment history that has the ID to roll back to.
+func (a *applicationCommand) historyEntry(app *unstructured.Unstructured) (map[string]interface{}, error) {
+	history, _, _ := unstructured.NestedSlice(app.Object, "status", "history")
+	for _, item := range history {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if id, _, _ := unstructured.NestedInt64(entry, "id"); id == a.historyID {
+			return entry, nil
+		}
+	}
+	return nil, fmt.Errorf("application %s (%s) has no deployment history with ID %d", a.applicationName, a.namespace, a.historyID)
+}
+
+func newApplicationOperation(sync map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"initiatedBy": map[string]interface{}{"username": operationInitiator},
+		"sync":        sync,
+	}
+}
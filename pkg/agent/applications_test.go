@@ -0,0 +1,217 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/datawire/ambassador-agent/pkg/api/agent"
+	"github.com/datawire/dlib/dlog"
+)
+
+var applicationGVR = schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "applications"}
+
+func newApplication(mutate func(obj map[string]interface{})) *unstructured.Unstructured {
+	obj := map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Application",
+		"metadata": map[string]interface{}{
+			"name":            "guestbook",
+			"namespace":       "argocd",
+			"resourceVersion": "7",
+		},
+		"spec": map[string]interface{}{
+			"source": map[string]interface{}{"repoURL": "https://github.com/argoproj/argocd-example-apps", "path": "guestbook"},
+		},
+		"status": map[string]interface{}{
+			"history": []interface{}{
+				map[string]interface{}{
+					"id":       int64(1),
+					"revision": "aaaa",
+					"source":   map[string]interface{}{"repoURL": "https://github.com/argoproj/argocd-example-apps", "path": "guestbook"},
+				},
+				map[string]interface{}{
+					"id":       int64(2),
+					"revision": "bbbb",
+					"source":   map[string]interface{}{"repoURL": "https://github.com/argoproj/argocd-example-apps", "path": "guestbook"},
+				},
+			},
+		},
+	}
+	if mutate != nil {
+		mutate(obj)
+	}
+	return &unstructured.Unstructured{Object: obj}
+}
+
+func runningOperation(obj map[string]interface{}) {
+	obj["operation"] = map[string]interface{}{"sync": map[string]interface{}{"revision": "bbbb"}}
+	obj["status"].(map[string]interface{})["operationState"] = map[string]interface{}{"phase": "Running"}
+}
+
+func TestApplicationCommand_RunWithClient(t *testing.T) {
+	type testcase struct {
+		app       *unstructured.Unstructured
+		cmd       applicationCommand
+		check     func(t *testing.T, app *unstructured.Unstructured)
+		expectErr string
+	}
+	cases := map[string]testcase{
+		"sync": {
+			app: newApplication(nil),
+			cmd: applicationCommand{action: applicationActionSync, revision: "cccc", prune: true},
+			check: func(t *testing.T, app *unstructured.Unstructured) {
+				sync, _, _ := unstructured.NestedMap(app.Object, "operation", "sync")
+				assert.Equal(t, map[string]interface{}{
+					"revision":     "cccc",
+					"prune":        true,
+					"syncStrategy": map[string]interface{}{"hook": map[string]interface{}{}},
+				}, sync)
+				user, _, _ := unstructured.NestedString(app.Object, "operation", "initiatedBy", "username")
+				assert.Equal(t, operationInitiator, user)
+			},
+		},
+		"sync while another operation runs": {
+			app:       newApplication(runningOperation),
+			cmd:       applicationCommand{action: applicationActionSync},
+			expectErr: "another operation is already in progress",
+		},
+		"hard refresh": {
+			app: newApplication(nil),
+			cmd: applicationCommand{action: applicationActionHardRefresh},
+			check: func(t *testing.T, app *unstructured.Unstructured) {
+				assert.Equal(t, "hard", app.GetAnnotations()[argoCDRefreshAnnotation])
+			},
+		},
+		"terminate operation": {
+			app: newApplication(runningOperation),
+			cmd: applicationCommand{action: applicationActionTerminateOperation},
+			check: func(t *testing.T, app *unstructured.Unstructured) {
+				phase, _, _ := unstructured.NestedString(app.Object, "status", "operationState", "phase")
+				assert.Equal(t, "Terminating", phase)
+			},
+		},
+		"terminate without an operation": {
+			app:       newApplication(nil),
+			cmd:       applicationCommand{action: applicationActionTerminateOperation},
+			expectErr: "unable to terminate operation, no operation is in progress",
+		},
+		"rollback": {
+			app: newApplication(nil),
+			cmd: applicationCommand{action: applicationActionRollback, historyID: 1},
+			check: func(t *testing.T, app *unstructured.Unstructured) {
+				revision, _, _ := unstructured.NestedString(app.Object, "operation", "sync", "revision")
+				assert.Equal(t, "aaaa", revision)
+				path, _, _ := unstructured.NestedString(app.Object, "operation", "sync", "source", "path")
+				assert.Equal(t, "guestbook", path)
+			},
+		},
+		"rollback to unknown history": {
+			app:       newApplication(nil),
+			cmd:       applicationCommand{action: applicationActionRollback, historyID: 3},
+			expectErr: "application guestbook (argocd) has no deployment history with ID 3",
+		},
+		"rollback with auto-sync": {
+			app: newApplication(func(obj map[string]interface{}) {
+				obj["spec"].(map[string]interface{})["syncPolicy"] = map[string]interface{}{"automated": map[string]interface{}{}}
+			}),
+			cmd:       applicationCommand{action: applicationActionRollback, historyID: 1},
+			expectErr: "rollback cannot be initiated when auto-sync is enabled",
+		},
+	}
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			client := newFakeDynamicClient(c.app)
+			cmd := c.cmd
+			cmd.applicationName = "guestbook"
+			cmd.namespace = "argocd"
+
+			err := cmd.RunWithClientFactory(ctx, func() (dynamic.Interface, error) { return client, nil })
+			app, getErr := client.Resource(applicationGVR).Namespace("argocd").Get(ctx, "guestbook", metav1.GetOptions{})
+			require.NoError(t, getErr)
+			if c.expectErr != "" {
+				assert.EqualError(t, err, c.expectErr)
+				assert.Equal(t, c.app, app, "application left alone")
+				return
+			}
+			require.NoError(t, err)
+			c.check(t, app)
+		})
+	}
+
+	t.Run("application not found", func(t *testing.T) {
+		cmd := &applicationCommand{applicationName: "other", namespace: "argocd", action: applicationActionSync}
+		err := cmd.RunWithClientFactory(context.Background(), func() (dynamic.Interface, error) {
+			return newFakeDynamicClient(newApplication(nil)), nil
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestHandleApplicationDirective(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	client := &MockClient{}
+	a := newTestAgent(client)
+	dynamicClient := newFakeDynamicClient(newApplication(nil))
+	dh := &BasicDirectiveHandler{
+		resourceClientFactory: func() (dynamic.Interface, error) { return dynamicClient, nil },
+	}
+
+	dh.HandleDirective(ctx, a, &agent.Directive{ID: "one", Commands: []*agent.Command{
+		{ApplicationCommand: &agent.ApplicationCommand{
+			CommandId: "rollback",
+			Name:      "guestbook",
+			Namespace: "argocd",
+			Action:    agent.ApplicationCommand_ROLLBACK,
+			HistoryId: 5,
+		}},
+		{ApplicationCommand: &agent.ApplicationCommand{
+			CommandId: "refresh",
+			Name:      "guestbook",
+			Namespace: "argocd",
+			Action:    agent.ApplicationCommand_HARD_REFRESH,
+		}},
+		{ApplicationCommand: &agent.ApplicationCommand{
+			CommandId: "dry run",
+			Name:      "guestbook",
+			Namespace: "argocd",
+			Action:    agent.ApplicationCommand_HARD_REFRESH,
+			DryRun:    true,
+		}},
+		{ApplicationCommand: &agent.ApplicationCommand{
+			CommandId: "missing action",
+			Name:      "guestbook",
+			Namespace: "argocd",
+		}},
+	}})
+
+	results := client.GetResults()
+	require.Len(t, results, 4)
+	assert.Equal(t, "rollback", results[0].CommandId)
+	assert.False(t, results[0].Success)
+	assert.Contains(t, results[0].Message, "no deployment history with ID 5")
+	assert.Equal(t, "refresh", results[1].CommandId)
+	assert.True(t, results[1].Success)
+	assert.False(t, results[1].DryRun)
+	assert.Equal(t, "dry run", results[2].CommandId)
+	assert.True(t, results[2].Success)
+	assert.True(t, results[2].DryRun)
+	assert.Equal(t, "missing action", results[3].CommandId)
+	assert.Equal(t, agent.CommandResult_FAILED, results[3].Status)
+
+	app, err := dynamicClient.Resource(applicationGVR).Namespace("argocd").Get(ctx, "guestbook", metav1.GetOptions{})
+	require.NoError(t, err)
+	_, synced := app.Object["operation"]
+	assert.False(t, synced, "no sync started")
+}
@@ -0,0 +1,181 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	agentapi "github.com/datawire/ambassador-agent/pkg/api/agent"
+	"github.com/datawire/dlib/dlog"
+)
+
+// auditOutcome is how a command ended, as recorded in the audit log.
+type auditOutcome string
+
+const (
+	auditOutcomeSucceeded = auditOutcome("succeeded")
+	auditOutcomeFailed    = auditOutcome("failed")
+	auditOutcomeTimeout   = auditOutcome("timeout")
+	auditOutcomeDenied    = auditOutcome("denied")
+)
+
+// auditRecord is an entry of the audit log. It describes what was done to
+// which object, never the data that was written, so that e.g. the values of
+// synced secrets don't end up in the logs.
+type auditRecord struct {
+	Time        time.Time    `json:"time"`
+	Audit       string       `json:"audit"` // "directive" or "command"
+	DirectiveID string       `json:"directiveID,omitempty"`
+	CommandID   string       `json:"commandID,omitempty"`
+	Command     commandKind  `json:"command,omitempty"`
+	Resource    string       `json:"resource,omitempty"`
+	Namespace   string       `json:"namespace,omitempty"`
+	Name        string       `json:"name,omitempty"`
+	Action      string       `json:"action,omitempty"`
+	Outcome     auditOutcome `json:"outcome,omitempty"`
+	Message     string       `json:"message,omitempty"`
+	DryRun      bool         `json:"dryRun,omitempty"`
+	Duration    *float64     `json:"durationSeconds,omitempty"` // commands only
+
+	// Commands is the number of commands of a directive.
+	Commands int `json:"commands,omitempty"`
+}
+
+// auditSink records the directives that the agent receives and the commands it
+// runs, as JSON lines, and optionally as Kubernetes Events on the objects that
+// the commands target.
+type auditSink struct {
+	mu  sync.Mutex
+	out io.Writer
+
+	// events is where Events are created. None are when nil.
+	events typedcorev1.EventsGetter
+}
+
+func newAuditSink(out io.Writer, events typedcorev1.EventsGetter) *auditSink {
+	return &auditSink{out: out, events: events}
+}
+
+// RecordDirective records that a directive was received.
+func (s *auditSink) RecordDirective(ctx context.Context, directive *agentapi.Directive) {
+	if s == nil {
+		return
+	}
+	s.write(ctx, &auditRecord{
+		Time:        time.Now(),
+		Audit:       "directive",
+		DirectiveID: directive.ID,
+		Commands:    len(directive.Commands),
+	})
+}
+
+// RecordCommand records the result of a command that ran, or was denied, on
+// the given target.
+func (s *auditSink) RecordCommand(
+	ctx context.Context, target commandTarget, result *agentapi.CommandResult, outcome auditOutcome, duration time.Duration,
+) {
+	if s == nil {
+		return
+	}
+	seconds := duration.Seconds()
+	record := &auditRecord{
+		Time:        time.Now(),
+		Audit:       "command",
+		DirectiveID: directiveIDFrom(ctx),
+		CommandID:   result.CommandId,
+		Command:     target.kind,
+		Resource:    target.resource,
+		Namespace:   target.namespace,
+		Name:        target.name,
+		Action:      target.action,
+		Outcome:     outcome,
+		Message:     result.Message,
+		DryRun:      result.DryRun,
+		Duration:    &seconds,
+	}
+	s.write(ctx, record)
+	if s.events != nil && target.object.Kind != "" {
+		s.createEvent(ctx, target, record)
+	}
+}
+
+func (s *auditSink) write(ctx context.Context, record *auditRecord) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		dlog.Errorf(ctx, "Unable to write audit record: %v", err)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err = s.out.Write(append(data, '\n')); err != nil {
+		dlog.Errorf(ctx, "Unable to write audit record: %v", err)
+	}
+}
+
+// createEvent creates an Event about the command on its target object, the way
+// kubectl describe shows them.
+func (s *auditSink) createEvent(ctx context.Context, target commandTarget, record *auditRecord) {
+	namespace := target.namespace
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	eventType, reason := corev1.EventTypeNormal, "CommandSucceeded"
+	switch record.Outcome {
+	case auditOutcomeSucceeded:
+	case auditOutcomeFailed:
+		eventType, reason = corev1.EventTypeWarning, "CommandFailed"
+	case auditOutcomeTimeout:
+		eventType, reason = corev1.EventTypeWarning, "CommandTimedOut"
+	case auditOutcomeDenied:
+		eventType, reason = corev1.EventTypeWarning, "CommandDenied"
+	}
+	message := fmt.Sprintf("%s %s requested by Ambassador Cloud (command %s, directive %s)",
+		target.kind, target.action, record.CommandID, record.DirectiveID)
+	if record.Message != "" {
+		message += ": " + record.Message
+	}
+
+	now := metav1.NewTime(record.Time)
+	apiVersion, kind := target.object.ToAPIVersionAndKind()
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", target.name, record.Time.UnixNano()),
+			Namespace: namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: apiVersion,
+			Kind:       kind,
+			Namespace:  target.namespace,
+			Name:       target.name,
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		Source:         corev1.EventSource{Component: operationInitiator},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	if _, err := s.events.Events(namespace).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		dlog.Errorf(ctx, "Unable to create event for %s command %s: %v", target.kind, record.CommandID, err)
+	}
+}
+
+type directiveIDKey struct{}
+
+// withDirectiveID returns a context that carries the ID of the directive being handled.
+func withDirectiveID(ctx context.Context, directiveID string) context.Context {
+	return context.WithValue(ctx, directiveIDKey{}, directiveID)
+}
+
+func directiveIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(directiveIDKey{}).(string)
+	return id
+}
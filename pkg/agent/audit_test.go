@@ -0,0 +1,126 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/datawire/ambassador-agent/pkg/api/agent"
+	"github.com/datawire/dlib/dlog"
+)
+
+func readAuditRecords(t *testing.T, out *bytes.Buffer) []auditRecord {
+	var records []auditRecord
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var record auditRecord
+		require.NoError(t, json.Unmarshal([]byte(line), &record), line)
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestAuditSink(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	client := &MockClient{}
+	a := newTestAgent(client)
+	var out bytes.Buffer
+	clientset := fake.NewSimpleClientset()
+	dh := &BasicDirectiveHandler{
+		secretsGetterFactory: wrapSecretGetterFactoryMock(newSecretGetterMock()),
+		audit:                newAuditSink(&out, clientset.CoreV1()),
+	}
+
+	dh.HandleDirective(ctx, a, &agent.Directive{ID: "one", Commands: []*agent.Command{
+		{SecretSyncCommand: &agent.SecretSyncCommand{
+			CommandId: "set",
+			Name:      "api-keys",
+			Namespace: "ambassador",
+			Action:    agent.SecretSyncCommand_SET,
+			Secret:    map[string][]byte{"key": []byte("s3cr3t-value")},
+		}},
+	}})
+
+	assert.NotContains(t, out.String(), "s3cr3t-value")
+	records := readAuditRecords(t, &out)
+	require.Len(t, records, 2)
+	assert.Equal(t, "directive", records[0].Audit)
+	assert.Equal(t, "one", records[0].DirectiveID)
+	assert.Equal(t, 1, records[0].Commands)
+	assert.Nil(t, records[0].Duration, "directives have no duration")
+	assert.NotContains(t, strings.SplitN(out.String(), "\n", 2)[0], "durationSeconds")
+
+	command := records[1]
+	assert.Equal(t, "command", command.Audit)
+	assert.Equal(t, "one", command.DirectiveID)
+	assert.Equal(t, "set", command.CommandID)
+	assert.Equal(t, commandKindSecretSync, command.Command)
+	assert.Equal(t, "secrets", command.Resource)
+	assert.Equal(t, "ambassador", command.Namespace)
+	assert.Equal(t, "api-keys", command.Name)
+	assert.Equal(t, "SET", command.Action)
+	assert.Equal(t, auditOutcomeSucceeded, command.Outcome)
+	assert.NotNil(t, command.Duration)
+
+	events, err := clientset.CoreV1().Events("ambassador").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, events.Items, 1)
+	event := events.Items[0]
+	assert.Equal(t, corev1.ObjectReference{APIVersion: "v1", Kind: "Secret", Namespace: "ambassador", Name: "api-keys"}, event.InvolvedObject)
+	assert.Equal(t, corev1.EventTypeNormal, event.Type)
+	assert.Equal(t, "CommandSucceeded", event.Reason)
+	assert.Equal(t, "secret-sync SET requested by Ambassador Cloud (command set, directive one)", event.Message)
+}
+
+func TestAuditSink_Outcomes(t *testing.T) {
+	ctx := withDirectiveID(dlog.NewTestContext(t, false), "two")
+	var out bytes.Buffer
+	clientset := fake.NewSimpleClientset()
+	s := newAuditSink(&out, clientset.CoreV1())
+
+	deployment := commandTarget{
+		kind:      commandKindDeployment,
+		resource:  "deployments.apps",
+		namespace: "default",
+		name:      "quote",
+		action:    "RESTART",
+		object:    schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+	}
+	s.RecordCommand(ctx, deployment, &agent.CommandResult{CommandId: "a", Message: "boom"}, auditOutcomeFailed, 0)
+	s.RecordCommand(ctx, deployment, &agent.CommandResult{CommandId: "b", Message: "no"}, auditOutcomeDenied, 0)
+
+	// no event without the kind of the object
+	patch := commandTarget{kind: commandKindResourcePatch, resource: "widgets.example.com", namespace: "default", name: "w", action: "MERGE"}
+	s.RecordCommand(ctx, patch, &agent.CommandResult{CommandId: "c"}, auditOutcomeTimeout, 0)
+
+	records := readAuditRecords(t, &out)
+	require.Len(t, records, 3)
+	assert.Equal(t, auditOutcomeFailed, records[0].Outcome)
+	assert.Equal(t, "boom", records[0].Message)
+	assert.Equal(t, auditOutcomeDenied, records[1].Outcome)
+	assert.Equal(t, auditOutcomeTimeout, records[2].Outcome)
+
+	events, err := clientset.CoreV1().Events("default").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	reasons := make([]string, 0, len(events.Items))
+	for _, event := range events.Items {
+		assert.Equal(t, corev1.EventTypeWarning, event.Type)
+		reasons = append(reasons, event.Reason)
+	}
+	assert.ElementsMatch(t, []string{"CommandFailed", "CommandDenied"}, reasons)
+
+	// a nil sink records nothing
+	var none *auditSink
+	none.RecordCommand(ctx, deployment, &agent.CommandResult{CommandId: "d"}, auditOutcomeSucceeded, 0)
+}
@@ -4,15 +4,21 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/url"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	"github.com/datawire/ambassador-agent/pkg/api/agent"
 	"github.com/datawire/dlib/dlog"
@@ -29,6 +35,12 @@ type RPCComm struct {
 	directives               chan *agent.Directive
 	metricsStreamWriterMutex sync.Mutex
 	extraHeaders             []string
+
+	// state holds the ConnState of the connection; backoff paces the
+	// attempts to retrieve directives while the Director can't be reached.
+	state     atomic.Int32
+	backoffMu sync.Mutex
+	backoff   backoff
 }
 
 const (
@@ -66,9 +78,14 @@ func connInfoFromAddress(address string) (*ConnInfo, error) {
 	return &ConnInfo{hostname, port, secure}, nil
 }
 
+// NewComm dials the Director. tlsConfig is used for secure connections, with
+// its ServerName set from connInfo; nil trusts the system roots. The Director is
+// reached through proxyURL when set, or through the proxy from the environment.
 func NewComm(
 	ctx context.Context,
 	connInfo *ConnInfo,
+	tlsConfig *tls.Config,
+	proxyURL *url.URL,
 	agentID *agent.Identity,
 	apiKey string,
 	extraHeaders []string,
@@ -79,11 +96,24 @@ func NewComm(
 
 	var creds credentials.TransportCredentials
 	if connInfo.secure {
-		creds = credentials.NewTLS(&tls.Config{ServerName: connInfo.hostname})
+		cfg := &tls.Config{}
+		if tlsConfig != nil {
+			cfg = tlsConfig.Clone()
+		}
+		cfg.ServerName = connInfo.hostname
+		creds = credentials.NewTLS(cfg)
 	} else {
 		creds = insecure.NewCredentials()
 	}
-	opts = append(opts, grpc.WithTransportCredentials(creds))
+	opts = append(opts,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithContextDialer(proxyDialer(directorProxyFunc(proxyURL), connInfo.secure)),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                keepaliveTime,
+			Timeout:             keepaliveTimeout,
+			PermitWithoutStream: true,
+		}),
+	)
 
 	dlog.Debugf(ctx, "Dialing server at %s (secure=%t)", address, connInfo.secure)
 
@@ -117,20 +147,101 @@ func (c *RPCComm) getHeaders(apiKey string) []string {
 	}, c.extraHeaders...)
 }
 
+// State returns the current state of the connection to the Director.
+func (c *RPCComm) State() ConnState {
+	return ConnState(c.state.Load())
+}
+
+func (c *RPCComm) setState(ctx context.Context, state ConnState, err error) {
+	old := ConnState(c.state.Swap(int32(state)))
+	if old == state {
+		return
+	}
+	switch state {
+	case ConnStateDegraded:
+		dlog.Warnf(ctx, "Director connection %s -> %s: %v", old, state, err)
+	case ConnStateAuthFailed:
+		dlog.Errorf(ctx, "Director connection %s -> %s, check CLOUD_CONNECT_TOKEN: %v", old, state, err)
+	default:
+		dlog.Infof(ctx, "Director connection %s -> %s", old, state)
+	}
+	if state == ConnStateReady {
+		c.backoffMu.Lock()
+		c.backoff.Reset()
+		c.backoffMu.Unlock()
+
+		// The Director can be reached again; don't wait for the backoff to
+		// restart the retriever.
+		select {
+		case c.rptWake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// observe updates the state of the connection with the outcome of an RPC.
+func (c *RPCComm) observe(ctx context.Context, err error) {
+	if ctx.Err() != nil {
+		// We gave up on the RPC; that says nothing about the connection
+		return
+	}
+	if _, ok := status.FromError(err); !ok {
+		// Failed before the RPC was made
+		return
+	}
+	switch status.Code(err) {
+	case codes.OK, codes.Unimplemented:
+		c.setState(ctx, ConnStateReady, nil)
+	case codes.Unauthenticated:
+		c.setState(ctx, ConnStateAuthFailed, err)
+	default:
+		c.setState(ctx, ConnStateDegraded, err)
+	}
+}
+
+// retryDelay returns how long to wait before retrieving directives again.
+func (c *RPCComm) retryDelay() time.Duration {
+	c.backoffMu.Lock()
+	defer c.backoffMu.Unlock()
+	delay := c.backoff.Next()
+	if c.State() == ConnStateAuthFailed {
+		delay = MaxDuration(delay, authFailedRetryDelay)
+	}
+	return delay
+}
+
 func (c *RPCComm) retrieveLoop(ctx context.Context) {
 	ctx = dlog.WithField(ctx, "agent", "retriever")
 
 	for {
-		if err := c.retrieve(ctx); err != nil {
-			dlog.Debugf(ctx, "exited: %+v", err)
+		err := c.retrieve(ctx)
+		if ctx.Err() != nil {
+			return
 		}
+		if errors.Is(err, io.EOF) {
+			// The Director ended the stream
+			err = nil
+		}
+		c.observe(ctx, err)
 
+		delay := c.retryDelay()
+		dlog.Debugf(ctx, "exited: %+v; restarting in %s", err, delay)
+		timer := time.NewTimer(delay)
 		select {
+		case <-timer.C:
 		case <-c.rptWake:
-			dlog.Debug(ctx, "restarting")
+			timer.Stop()
 		case <-ctx.Done():
+			timer.Stop()
 			return
 		}
+		dlog.Debug(ctx, "restarting")
+
+		if c.State() == ConnStateAuthFailed {
+			// Give the token another chance; reports are let through again
+			// until the Director rejects it anew.
+			c.setState(ctx, ConnStateConnecting, nil)
+		}
 	}
 }
 
@@ -145,6 +256,7 @@ func (c *RPCComm) retrieve(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
+		c.observe(ctx, nil)
 
 		select {
 		case c.directives <- directive:
@@ -162,17 +274,15 @@ func (c *RPCComm) Close() error {
 func (c *RPCComm) ReportCommandResult(ctx context.Context, result *agent.CommandResult, apiKey string) error {
 	ctx = metadata.AppendToOutgoingContext(ctx, c.getHeaders(apiKey)...)
 	_, err := c.client.ReportCommandResult(ctx, result, grpc.EmptyCallOption{})
+	c.observe(ctx, err)
 	if err != nil {
 		return fmt.Errorf("ReportCommandResult error: %w", err)
 	}
 	return nil
 }
 
-func (c *RPCComm) Report(ctx context.Context, report *agent.Snapshot, apiKey string) error {
-	select {
-	case c.rptWake <- struct{}{}:
-	default:
-	}
+func (c *RPCComm) Report(ctx context.Context, report *agent.Snapshot, apiKey string) (err error) {
+	defer func() { c.observe(ctx, err) }()
 	ctx = metadata.AppendToOutgoingContext(ctx, c.getHeaders(apiKey)...)
 
 	// marshal snapshot
@@ -181,10 +291,7 @@ func (c *RPCComm) Report(ctx context.Context, report *agent.Snapshot, apiKey str
 		return fmt.Errorf("json.Marshal: %w", err)
 	}
 
-	const CHUNKSIZE = (64 * 1024) - 4 // 64KiB-4B; gRPC adds 4 bytes of overhead
-	dlog.Debugf(ctx, "Report is %dB; will take %d chunks",
-		len(data),
-		(len(data)+CHUNKSIZE-1)/CHUNKSIZE)
+	dlog.Debugf(ctx, "Report is %dB; will take %d chunks", len(data), chunkCount(data))
 
 	// make stream
 	stream, err := c.client.ReportStream(ctx)
@@ -194,29 +301,60 @@ func (c *RPCComm) Report(ctx context.Context, report *agent.Snapshot, apiKey str
 
 	// send chunks
 	msg := &agent.RawSnapshotChunk{}
-	for i := 0; i < len(data); i += CHUNKSIZE {
-		j := i + CHUNKSIZE
+	err = sendChunks(data, func(chunk []byte) error {
+		msg.Chunk = chunk
+		return stream.Send(msg)
+	})
+	if err != nil {
+		return fmt.Errorf("ReportStream.Send: %w", err)
+	}
 
-		if j < len(data) {
-			msg.Chunk = data[i:j]
-		} else {
-			msg.Chunk = data[i:]
-		}
+	if _, err = stream.CloseAndRecv(); err != nil {
+		return fmt.Errorf("ReportStream.Close: %w", err)
+	}
 
-		if err := stream.Send(msg); err != nil {
-			return fmt.Errorf("ReportStream.Send: %w", err)
-		}
+	return nil
+}
+
+func (c *RPCComm) ReportDelta(ctx context.Context, delta *agent.SnapshotDelta, apiKey string) (err error) {
+	defer func() { c.observe(ctx, err) }()
+	ctx = metadata.AppendToOutgoingContext(ctx, c.getHeaders(apiKey)...)
+
+	// marshal delta
+	data, err := json.Marshal(delta)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	dlog.Debugf(ctx, "Delta report with %d objects is %dB; will take %d chunks",
+		len(delta.Deltas), len(data), chunkCount(data))
+
+	// make stream
+	stream, err := c.client.ReportDeltaStream(ctx)
+	if err != nil {
+		return fmt.Errorf("ReportDeltaStream.Open: %w", err)
+	}
+
+	// send chunks
+	msg := &agent.RawSnapshotDeltaChunk{}
+	err = sendChunks(data, func(chunk []byte) error {
+		msg.Chunk = chunk
+		return stream.Send(msg)
+	})
+	if err != nil {
+		return fmt.Errorf("ReportDeltaStream.Send: %w", err)
 	}
 
 	if _, err = stream.CloseAndRecv(); err != nil {
-		return fmt.Errorf("ReportStream.Close: %w", err)
+		return fmt.Errorf("ReportDeltaStream.Close: %w", err)
 	}
 
 	return nil
 }
 
-func (c *RPCComm) StreamMetrics(ctx context.Context, metrics *agent.StreamMetricsMessage, apiKey string) error {
+func (c *RPCComm) StreamMetrics(ctx context.Context, metrics *agent.StreamMetricsMessage, apiKey string) (err error) {
 	ctx = dlog.WithField(ctx, "agent", "streammetrics")
+	defer func() { c.observe(ctx, err) }()
 
 	c.metricsStreamWriterMutex.Lock()
 	defer c.metricsStreamWriterMutex.Unlock()
@@ -226,22 +364,41 @@ func (c *RPCComm) StreamMetrics(ctx context.Context, metrics *agent.StreamMetric
 		return err
 	}
 
-	if err := streamClient.Send(metrics); err != nil {
+	if err = streamClient.Send(metrics); err != nil {
+		return err
+	}
+
+	_, err = streamClient.CloseAndRecv()
+	return err
+}
+
+// StreamLogs opens a stream of logs to the Director and calls send with the
+// function that sends a chunk on it. The stream is closed once send returns.
+func (c *RPCComm) StreamLogs(ctx context.Context, apiKey string, send func(func(*agent.LogsChunk) error) error) (err error) {
+	ctx = dlog.WithField(ctx, "agent", "streamlogs")
+	defer func() { c.observe(ctx, err) }()
+
+	ctx = metadata.AppendToOutgoingContext(ctx, c.getHeaders(apiKey)...)
+	streamClient, err := c.client.StreamLogs(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = send(streamClient.Send); err != nil {
+		_ = streamClient.CloseSend()
 		return err
 	}
 
-	return streamClient.CloseSend()
+	_, err = streamClient.CloseAndRecv()
+	return err
 }
 
 func (c *RPCComm) Directives() <-chan *agent.Directive {
 	return c.directives
 }
 
-func (c *RPCComm) StreamDiagnostics(ctx context.Context, diagnosticsReport *agent.Diagnostics, apiKey string) error {
-	select {
-	case c.rptWake <- struct{}{}:
-	default:
-	}
+func (c *RPCComm) StreamDiagnostics(ctx context.Context, diagnosticsReport *agent.Diagnostics, apiKey string) (err error) {
+	defer func() { c.observe(ctx, err) }()
 	ctx = metadata.AppendToOutgoingContext(ctx, c.getHeaders(apiKey)...)
 
 	// marshal diagnostics into bytes
@@ -250,10 +407,7 @@ func (c *RPCComm) StreamDiagnostics(ctx context.Context, diagnosticsReport *agen
 		return fmt.Errorf("json.Marshal: %w", err)
 	}
 
-	const CHUNKSIZE = (64 * 1024) - 4 // 64KiB-4B; gRPC adds 4 bytes of overhead
-	dlog.Debugf(ctx, "Diagnostics Report is %dB; will take %d chunks",
-		len(data),
-		(len(data)+CHUNKSIZE-1)/CHUNKSIZE)
+	dlog.Debugf(ctx, "Diagnostics Report is %dB; will take %d chunks", len(data), chunkCount(data))
 
 	// make stream
 	stream, err := c.client.StreamDiagnostics(ctx)
@@ -263,18 +417,12 @@ func (c *RPCComm) StreamDiagnostics(ctx context.Context, diagnosticsReport *agen
 
 	// send chunks
 	msg := &agent.RawDiagnosticsChunk{}
-	for i := 0; i < len(data); i += CHUNKSIZE {
-		j := i + CHUNKSIZE
-
-		if j < len(data) {
-			msg.Chunk = data[i:j]
-		} else {
-			msg.Chunk = data[i:]
-		}
-
-		if err := stream.Send(msg); err != nil {
-			return fmt.Errorf("ReportDiagnosticsStream.Send: %w", err)
-		}
+	err = sendChunks(data, func(chunk []byte) error {
+		msg.Chunk = chunk
+		return stream.Send(msg)
+	})
+	if err != nil {
+		return fmt.Errorf("ReportDiagnosticsStream.Send: %w", err)
 	}
 
 	if _, err = stream.CloseAndRecv(); err != nil {
@@ -283,3 +431,25 @@ func (c *RPCComm) StreamDiagnostics(ctx context.Context, diagnosticsReport *agen
 
 	return nil
 }
+
+const chunkSize = (64 * 1024) - 4 // 64KiB-4B; gRPC adds 4 bytes of overhead
+
+// chunkCount returns the number of chunks that sendChunks will use for data.
+func chunkCount(data []byte) int {
+	return (len(data) + chunkSize - 1) / chunkSize
+}
+
+// sendChunks splits data into chunks that fit in a single gRPC message and
+// calls send for each of them.
+func sendChunks(data []byte, send func([]byte) error) error {
+	for i := 0; i < len(data); i += chunkSize {
+		j := i + chunkSize
+		if j > len(data) {
+			j = len(data)
+		}
+		if err := send(data[i:j]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -20,15 +20,36 @@ import (
 type MockClient struct {
 	Counter int64
 	grpc.ClientStream
-	SentMetrics   []*agent.StreamMetricsMessage
-	SentSnapshots []*agent.Snapshot
-	snapMux       sync.Mutex
-	reportFunc    func(context.Context, *agent.Snapshot) (*agent.SnapshotResponse, error)
-	LastMetadata  metadata.MD
+	SentMetrics     []*agent.StreamMetricsMessage
+	SentSnapshots   []*agent.Snapshot
+	SentDeltas      []*agent.SnapshotDelta
+	SentDiagnostics []*agent.Diagnostics
+	SentResults     []*agent.CommandResult
+	SentLogs        []*agent.LogsChunk
+	snapMux         sync.Mutex
+	reportFunc      func(context.Context, *agent.Snapshot) (*agent.SnapshotResponse, error)
+	deltaFunc       func(context.Context, *agent.SnapshotDelta) (*agent.SnapshotResponse, error)
+	resultFunc      func(context.Context, *agent.CommandResult) (*agent.CommandResultResponse, error)
+	LastMetadata    metadata.MD
 }
 
 func (m *MockClient) ReportCommandResult(ctx context.Context, in *agent.CommandResult, opts ...grpc.CallOption) (*agent.CommandResultResponse, error) {
-	panic("implement me")
+	m.snapMux.Lock()
+	defer m.snapMux.Unlock()
+	if m.resultFunc != nil {
+		if resp, err := m.resultFunc(ctx, in); err != nil {
+			return resp, err
+		}
+	}
+	m.SentResults = append(m.SentResults, in)
+	return &agent.CommandResultResponse{}, nil
+}
+
+func (m *MockClient) GetResults() []*agent.CommandResult {
+	m.snapMux.Lock()
+	defer m.snapMux.Unlock()
+	results := m.SentResults
+	return results
 }
 
 func (m *MockClient) Close() error {
@@ -77,6 +98,13 @@ func (s *mockStreamDiagnosticsClient) Send(chunk *agent.RawDiagnosticsChunk) err
 }
 
 func (s *mockStreamDiagnosticsClient) CloseAndRecv() (*agent.DiagnosticsResponse, error) {
+	var diagnostics agent.Diagnostics
+	if err := json.Unmarshal(s.content, &diagnostics); err != nil {
+		return nil, err
+	}
+	s.parent.snapMux.Lock()
+	defer s.parent.snapMux.Unlock()
+	s.parent.SentDiagnostics = append(s.parent.SentDiagnostics, &diagnostics)
 	return nil, nil
 }
 
@@ -110,6 +138,8 @@ type mockStreamMetricsClient struct {
 }
 
 func (s *mockStreamMetricsClient) Send(msg *agent.StreamMetricsMessage) error {
+	s.parent.snapMux.Lock()
+	defer s.parent.snapMux.Unlock()
 	s.parent.SentMetrics = append(s.parent.SentMetrics, msg)
 	return nil
 }
@@ -125,6 +155,36 @@ func (s *mockStreamMetricsClient) Context() context.Context     { return s.ctx }
 func (s *mockStreamMetricsClient) SendMsg(m interface{}) error  { return nil }
 func (s *mockStreamMetricsClient) RecvMsg(m interface{}) error  { return nil }
 
+func (m *MockClient) StreamLogs(ctx context.Context, opts ...grpc.CallOption) (agent.Director_StreamLogsClient, error) {
+	return &mockStreamLogsClient{
+		ctx:    ctx,
+		parent: m,
+	}, nil
+}
+
+type mockStreamLogsClient struct {
+	ctx    context.Context
+	parent *MockClient
+}
+
+func (s *mockStreamLogsClient) Send(chunk *agent.LogsChunk) error {
+	s.parent.snapMux.Lock()
+	defer s.parent.snapMux.Unlock()
+	s.parent.SentLogs = append(s.parent.SentLogs, chunk)
+	return nil
+}
+
+func (s *mockStreamLogsClient) CloseAndRecv() (*agent.LogsResponse, error) {
+	return &agent.LogsResponse{}, nil
+}
+
+func (s *mockStreamLogsClient) Header() (metadata.MD, error) { return nil, nil }
+func (s *mockStreamLogsClient) Trailer() metadata.MD         { return nil }
+func (s *mockStreamLogsClient) CloseSend() error             { return nil }
+func (s *mockStreamLogsClient) Context() context.Context     { return s.ctx }
+func (s *mockStreamLogsClient) SendMsg(m interface{}) error  { return nil }
+func (s *mockStreamLogsClient) RecvMsg(m interface{}) error  { return nil }
+
 type mockReportStreamClient struct {
 	ctx     context.Context
 	opts    []grpc.CallOption
@@ -160,6 +220,79 @@ func (m *MockClient) ReportStream(ctx context.Context, opts ...grpc.CallOption)
 	}, nil
 }
 
+func (m *MockClient) GetDiagnostics() []*agent.Diagnostics {
+	m.snapMux.Lock()
+	defer m.snapMux.Unlock()
+	diagnostics := m.SentDiagnostics
+	return diagnostics
+}
+
+func (m *MockClient) GetMetrics() []*agent.StreamMetricsMessage {
+	m.snapMux.Lock()
+	defer m.snapMux.Unlock()
+	metrics := m.SentMetrics
+	return metrics
+}
+
+func (m *MockClient) GetLogs() []*agent.LogsChunk {
+	m.snapMux.Lock()
+	defer m.snapMux.Unlock()
+	logs := m.SentLogs
+	return logs
+}
+
+func (m *MockClient) GetDeltas() []*agent.SnapshotDelta {
+	m.snapMux.Lock()
+	defer m.snapMux.Unlock()
+	deltas := m.SentDeltas
+	return deltas
+}
+
+func (m *MockClient) reportDelta(ctx context.Context, in *agent.SnapshotDelta) (*agent.SnapshotResponse, error) {
+	m.snapMux.Lock()
+	defer m.snapMux.Unlock()
+	m.SentDeltas = append(m.SentDeltas, in)
+	md, _ := metadata.FromOutgoingContext(ctx)
+	m.LastMetadata = md
+	if m.deltaFunc != nil {
+		return m.deltaFunc(ctx, in)
+	}
+	return nil, nil
+}
+
+type mockReportDeltaStreamClient struct {
+	ctx     context.Context
+	parent  *MockClient
+	content []byte
+}
+
+func (s *mockReportDeltaStreamClient) Send(chunk *agent.RawSnapshotDeltaChunk) error {
+	s.content = append(s.content, chunk.Chunk...)
+	return nil
+}
+
+func (s *mockReportDeltaStreamClient) CloseAndRecv() (*agent.SnapshotResponse, error) {
+	var delta agent.SnapshotDelta
+	if err := json.Unmarshal(s.content, &delta); err != nil {
+		return nil, err
+	}
+	return s.parent.reportDelta(s.ctx, &delta)
+}
+
+func (s *mockReportDeltaStreamClient) Header() (metadata.MD, error) { return nil, nil }
+func (s *mockReportDeltaStreamClient) Trailer() metadata.MD         { return nil }
+func (s *mockReportDeltaStreamClient) CloseSend() error             { return nil }
+func (s *mockReportDeltaStreamClient) Context() context.Context     { return s.ctx }
+func (s *mockReportDeltaStreamClient) SendMsg(m interface{}) error  { return nil }
+func (s *mockReportDeltaStreamClient) RecvMsg(m interface{}) error  { return nil }
+
+func (m *MockClient) ReportDeltaStream(ctx context.Context, opts ...grpc.CallOption) (agent.Director_ReportDeltaStreamClient, error) {
+	return &mockReportDeltaStreamClient{
+		ctx:    ctx,
+		parent: m,
+	}, nil
+}
+
 func (m *MockClient) Recv() (*agent.Directive, error) {
 	counter := atomic.AddInt64(&m.Counter, 1)
 
@@ -0,0 +1,217 @@
+package agent
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/datawire/ambassador-agent/pkg/api/agent"
+	"github.com/datawire/dlib/dlog"
+)
+
+const (
+	// commandCacheKey is the ConfigMap key that holds the persisted command results.
+	commandCacheKey = "command-results.json"
+	// commandCachePersistDelay is how long the results of the commands that complete
+	// together are collected before they are persisted at once.
+	commandCachePersistDelay = time.Second
+)
+
+// commandCacheLabels are the labels of the ConfigMap of the cache. They don't include
+// managedByLabel, as configmap sync commands may change the ConfigMaps that have it.
+var commandCacheLabels = map[string]string{
+	"app.kubernetes.io/part-of":   managedByAgent,
+	"app.kubernetes.io/component": "command-cache",
+}
+
+// commandResultCache remembers the results of the last commands that were
+// executed, so that a command that the Director delivers again is not executed
+// again. It is a bounded LRU, optionally persisted to a ConfigMap so that it
+// survives restarts. A nil cache remembers nothing.
+type commandResultCache struct {
+	max int
+
+	mu    sync.Mutex
+	order *list.List // of *agent.CommandResult, most recently used first
+	byID  map[string]*list.Element
+
+	configMaps    ConfigMapInterface // nil when not persisted
+	configMapName string
+	// persist is signaled when the cache changed. The cache is persisted in the
+	// background, without holding mu, so that a slow API server doesn't hold up
+	// the directives that look up the cache.
+	persist      chan struct{}
+	persistDelay time.Duration
+}
+
+func newCommandResultCache(max int) *commandResultCache {
+	return &commandResultCache{
+		max:          max,
+		order:        list.New(),
+		byID:         make(map[string]*list.Element),
+		persist:      make(chan struct{}, 1),
+		persistDelay: commandCachePersistDelay,
+	}
+}
+
+// persistTo loads the results persisted in the named ConfigMap, if it exists,
+// and persists the cache there from now on, until the context is cancelled.
+func (c *commandResultCache) persistTo(ctx context.Context, configMaps ConfigMapInterface, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.configMaps = configMaps
+	c.configMapName = name
+	go c.persistInBackground(ctx)
+
+	cm, err := configMaps.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get the configmap %s: %w", name, err)
+	}
+	data, ok := cm.Data[commandCacheKey]
+	if !ok {
+		return nil
+	}
+	var results []*agent.CommandResult // oldest first
+	if err := json.Unmarshal([]byte(data), &results); err != nil {
+		return fmt.Errorf("unable to parse %s of the configmap %s: %w", commandCacheKey, name, err)
+	}
+	for _, result := range results {
+		c.add(result)
+	}
+	return nil
+}
+
+// Get returns the result of the command with the given ID, if it was executed.
+func (c *commandResultCache) Get(commandID string) (*agent.CommandResult, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.byID[commandID]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*agent.CommandResult), true
+}
+
+// Add remembers the result of an executed command, forgetting the least
+// recently used one when the cache is full.
+func (c *commandResultCache) Add(ctx context.Context, result *agent.CommandResult) {
+	if c == nil || result.GetCommandId() == "" {
+		return
+	}
+	c.mu.Lock()
+	c.add(result)
+	persisted := c.configMaps != nil
+	c.mu.Unlock()
+	if persisted {
+		select {
+		case c.persist <- struct{}{}:
+		default:
+			// already signaled, the result will be persisted with the others
+		}
+	}
+}
+
+func (c *commandResultCache) add(result *agent.CommandResult) {
+	if e, ok := c.byID[result.CommandId]; ok {
+		e.Value = result
+		c.order.MoveToFront(e)
+		return
+	}
+	c.byID[result.CommandId] = c.order.PushFront(result)
+	for c.order.Len() > c.max {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.byID, oldest.Value.(*agent.CommandResult).CommandId)
+	}
+}
+
+// persistInBackground persists the cache when it changes, at most once per
+// persistDelay, until the context is cancelled. The last results are persisted
+// each time, so that the ConfigMap ends up holding the latest ones.
+func (c *commandResultCache) persistInBackground(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.persist:
+		}
+
+		select {
+		case <-ctx.Done():
+			// persist the last results before the agent stops
+			saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+			c.saveOrWarn(saveCtx)
+			cancel()
+			return
+		case <-time.After(c.persistDelay):
+		}
+		c.saveOrWarn(ctx)
+	}
+}
+
+func (c *commandResultCache) saveOrWarn(ctx context.Context) {
+	if err := c.save(ctx); err != nil {
+		dlog.Warnf(ctx, "unable to persist command results: %v", err)
+	}
+}
+
+// results returns the results in the cache, oldest first.
+func (c *commandResultCache) results() []*agent.CommandResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	results := make([]*agent.CommandResult, 0, c.order.Len())
+	for e := c.order.Back(); e != nil; e = e.Prev() {
+		results = append(results, e.Value.(*agent.CommandResult))
+	}
+	return results
+}
+
+// save writes the cache to its ConfigMap.
+func (c *commandResultCache) save(ctx context.Context) error {
+	data, err := json.Marshal(c.results())
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	cm, err := c.configMaps.Get(ctx, c.configMapName, metav1.GetOptions{})
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get the configmap %s: %w", c.configMapName, err)
+		}
+		_, err = c.configMaps.Create(ctx, &apiv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: c.configMapName, Labels: commandCacheLabels},
+			Data:       map[string]string{commandCacheKey: string(data)},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to create the configmap %s: %w", c.configMapName, err)
+		}
+		return nil
+	}
+	if cm.Data == nil {
+		cm.Data = make(map[string]string)
+	}
+	cm.Data[commandCacheKey] = string(data)
+	if _, err = c.configMaps.Update(ctx, cm, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update the configmap %s: %w", c.configMapName, err)
+	}
+	return nil
+}
+
+// commandCache tells whether the ConfigMap is the one the command results are persisted to.
+func (e *Env) commandCache(namespace, name string) bool {
+	return e != nil && e.CommandCacheConfigMap != "" && e.AgentNamespace == namespace && e.CommandCacheConfigMap == name
+}
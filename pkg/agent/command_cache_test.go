@@ -0,0 +1,124 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	appsv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+
+	"github.com/datawire/ambassador-agent/pkg/api/agent"
+	"github.com/datawire/dlib/dlog"
+)
+
+func TestCommandResultCache(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+
+	t.Run("least recently used results are forgotten", func(t *testing.T) {
+		c := newCommandResultCache(2)
+		c.Add(ctx, &agent.CommandResult{CommandId: "1", Success: true})
+		c.Add(ctx, &agent.CommandResult{CommandId: "2", Success: true})
+		_, ok := c.Get("1")
+		assert.True(t, ok)
+		c.Add(ctx, &agent.CommandResult{CommandId: "3", Success: true})
+
+		_, ok = c.Get("2")
+		assert.False(t, ok)
+		result, ok := c.Get("1")
+		require.True(t, ok)
+		assert.True(t, result.Success)
+		_, ok = c.Get("3")
+		assert.True(t, ok)
+	})
+
+	t.Run("nil cache", func(t *testing.T) {
+		var c *commandResultCache
+		c.Add(ctx, &agent.CommandResult{CommandId: "1"})
+		_, ok := c.Get("1")
+		assert.False(t, ok)
+	})
+
+	t.Run("persisted to a configmap", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		clientset := fake.NewSimpleClientset()
+		configMaps := clientset.CoreV1().ConfigMaps("ambassador")
+		c := newCommandResultCache(2)
+		c.persistDelay = 50 * time.Millisecond
+		require.NoError(t, c.persistTo(ctx, configMaps, "agent-commands"))
+		c.Add(ctx, &agent.CommandResult{CommandId: "1", Success: true})
+		c.Add(ctx, &agent.CommandResult{CommandId: "2", Message: "boom"})
+		c.Add(ctx, &agent.CommandResult{CommandId: "3", Success: true})
+
+		// the results are persisted in the background, all at once
+		var cm *apiv1.ConfigMap
+		require.Eventually(t, func() bool {
+			var err error
+			cm, err = configMaps.Get(ctx, "agent-commands", metav1.GetOptions{})
+			return err == nil
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Contains(t, cm.Data, commandCacheKey)
+		assert.Equal(t, commandCacheLabels, cm.Labels, "not changed by configmap sync commands")
+		writes := 0
+		for _, action := range clientset.Actions() {
+			if action.GetVerb() == "create" || action.GetVerb() == "update" {
+				writes++
+			}
+		}
+		assert.Equal(t, 1, writes)
+
+		// a restarted agent remembers them
+		restarted := newCommandResultCache(2)
+		require.NoError(t, restarted.persistTo(ctx, configMaps, "agent-commands"))
+		result, ok := restarted.Get("2")
+		require.True(t, ok)
+		assert.Equal(t, "boom", result.Message)
+		_, ok = restarted.Get("3")
+		assert.True(t, ok)
+		_, ok = restarted.Get("1")
+		assert.False(t, ok)
+	})
+}
+
+func TestHandleRedeliveredCommand(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	client := &MockClient{}
+	a := newTestAgent(client)
+	a.commandResults = newCommandResultCache(10)
+	clientset := fake.NewSimpleClientset()
+	runs := 0
+	dh := &BasicDirectiveHandler{
+		deploymentsGetterFactory: func() (appsv1.DeploymentsGetter, error) {
+			runs++
+			return clientset.AppsV1(), nil
+		},
+	}
+
+	directive := &agent.Directive{ID: "one", Commands: []*agent.Command{
+		{DeploymentCommand: &agent.DeploymentCommand{
+			CommandId: "restart",
+			Name:      "quote",
+			Namespace: "default",
+			Action:    agent.DeploymentCommand_RESTART,
+		}},
+	}}
+	dh.HandleDirective(ctx, a, directive)
+	dh.HandleDirective(ctx, a, directive)
+
+	assert.Equal(t, 1, runs, "command executed once")
+	results := client.GetResults()
+	require.Len(t, results, 2)
+	for _, result := range results {
+		assert.Equal(t, "restart", result.CommandId)
+		assert.False(t, result.Success, "the deployment doesn't exist")
+	}
+	assert.Equal(t, results[0].Message, results[1].Message)
+}
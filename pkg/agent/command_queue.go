@@ -0,0 +1,155 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// commandKind identifies a type of command, e.g. to configure its timeout.
+type commandKind string
+
+const (
+	commandKindRollout       = commandKind("rollout")
+	commandKindDeployment    = commandKind("deployment")
+	commandKindApplication   = commandKind("application")
+	commandKindSecretSync    = commandKind("secret-sync")
+	commandKindConfigMapSync = commandKind("configmap-sync")
+	commandKindResourcePatch = commandKind("resource-patch")
+	commandKindLogs          = commandKind("logs")
+)
+
+func (k commandKind) valid() bool {
+	switch k {
+	case commandKindRollout, commandKindDeployment, commandKindApplication,
+		commandKindSecretSync, commandKindConfigMapSync, commandKindResourcePatch, commandKindLogs:
+		return true
+	default:
+		return false
+	}
+}
+
+// commandTarget describes what a command does to which object.
+type commandTarget struct {
+	kind      commandKind
+	resource  string // the group resource of the object, e.g. deployments.apps
+	namespace string
+	name      string
+	action    string
+
+	// object is the kind of the object, when known, e.g. to create Events about it.
+	object schema.GroupVersionKind
+}
+
+// key identifies the object of the command.
+func (t commandTarget) key() string {
+	return t.resource + "/" + t.namespace + "/" + t.name
+}
+
+// parseCommandTimeouts parses space-separated kind=duration pairs, e.g.
+// "rollout=30s application=2m".
+func parseCommandTimeouts(str string) (map[commandKind]time.Duration, error) {
+	timeouts := make(map[commandKind]time.Duration)
+	for _, pair := range strings.Fields(str) {
+		kind, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid command timeout %q, must be kind=duration", pair)
+		}
+		if !commandKind(kind).valid() {
+			return nil, fmt.Errorf("invalid command timeout %q, unknown command kind %q", pair, kind)
+		}
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid command timeout %q: %w", pair, err)
+		}
+		timeouts[commandKind(kind)] = timeout
+	}
+	return timeouts, nil
+}
+
+// commandTimeout returns how long a command of the given kind may run. Zero
+// means no limit.
+func (e *Env) commandTimeout(kind commandKind) time.Duration {
+	if e == nil {
+		return 0
+	}
+	if timeout, ok := e.CommandTimeouts[kind]; ok {
+		return timeout
+	}
+	return e.CommandTimeout
+}
+
+// commandsDryRun tells whether all commands are dry runs.
+func (e *Env) commandsDryRun() bool {
+	return e != nil && e.CommandsDryRun
+}
+
+// commandQueue runs commands in the background on a bounded number of
+// workers. Commands on the same target object run one at a time, in the order
+// they were submitted, while commands on different targets run concurrently.
+type commandQueue struct {
+	workers chan struct{} // holds a token for every busy worker
+
+	mu       sync.Mutex
+	targets  map[string][]func() // pending commands, for targets with a command running
+	inFlight map[string]struct{} // IDs of the queued and running commands
+}
+
+func newCommandQueue(workers int) *commandQueue {
+	return &commandQueue{
+		workers:  make(chan struct{}, workers),
+		targets:  make(map[string][]func()),
+		inFlight: make(map[string]struct{}),
+	}
+}
+
+// Submit queues the command with the given ID to run on the given target. It
+// returns false, and doesn't queue anything, when that command is already
+// queued or running.
+func (q *commandQueue) Submit(commandID, target string, run func()) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.inFlight[commandID]; ok {
+		return false
+	}
+	q.inFlight[commandID] = struct{}{}
+
+	job := func() {
+		defer func() {
+			q.mu.Lock()
+			delete(q.inFlight, commandID)
+			q.mu.Unlock()
+		}()
+		run()
+	}
+	if pending, busy := q.targets[target]; busy {
+		q.targets[target] = append(pending, job)
+		return true
+	}
+	q.targets[target] = []func(){job}
+	go q.drain(target)
+	return true
+}
+
+// drain runs the commands of a target until there are none left.
+func (q *commandQueue) drain(target string) {
+	for {
+		q.mu.Lock()
+		pending := q.targets[target]
+		if len(pending) == 0 {
+			delete(q.targets, target)
+			q.mu.Unlock()
+			return
+		}
+		job := pending[0]
+		q.targets[target] = pending[1:]
+		q.mu.Unlock()
+
+		q.workers <- struct{}{}
+		job()
+		<-q.workers
+	}
+}
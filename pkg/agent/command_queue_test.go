@@ -0,0 +1,150 @@
+package agent
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiappsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	appsv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+
+	"github.com/datawire/ambassador-agent/pkg/api/agent"
+	"github.com/datawire/dlib/dlog"
+)
+
+func TestParseCommandTimeouts(t *testing.T) {
+	timeouts, err := parseCommandTimeouts("rollout=30s  application=2m")
+	require.NoError(t, err)
+	assert.Equal(t, map[commandKind]time.Duration{
+		commandKindRollout:     30 * time.Second,
+		commandKindApplication: 2 * time.Minute,
+	}, timeouts)
+
+	env := &Env{CommandTimeout: time.Minute, CommandTimeouts: timeouts}
+	assert.Equal(t, 30*time.Second, env.commandTimeout(commandKindRollout))
+	assert.Equal(t, time.Minute, env.commandTimeout(commandKindDeployment))
+
+	_, err = parseCommandTimeouts("rollout")
+	assert.Error(t, err)
+	_, err = parseCommandTimeouts("pod=1s")
+	assert.Error(t, err)
+	_, err = parseCommandTimeouts("rollout=soon")
+	assert.Error(t, err)
+}
+
+func TestCommandQueue(t *testing.T) {
+	t.Run("commands on the same target run in order, one at a time", func(t *testing.T) {
+		q := newCommandQueue(4)
+		var (
+			mu      sync.Mutex
+			order   []int
+			running int
+			overlap bool
+			done    sync.WaitGroup
+		)
+		for i := 0; i < 5; i++ {
+			i := i
+			done.Add(1)
+			require.True(t, q.Submit(string(rune('a'+i)), "deployments.apps/default/quote", func() {
+				defer done.Done()
+				mu.Lock()
+				running++
+				overlap = overlap || running > 1
+				order = append(order, i)
+				mu.Unlock()
+				time.Sleep(time.Millisecond)
+				mu.Lock()
+				running--
+				mu.Unlock()
+			}))
+		}
+		done.Wait()
+		assert.False(t, overlap)
+		assert.Equal(t, []int{0, 1, 2, 3, 4}, order)
+	})
+
+	t.Run("commands on different targets run concurrently", func(t *testing.T) {
+		q := newCommandQueue(2)
+		release := make(chan struct{})
+		started := make(chan string, 2)
+		for _, target := range []string{"one", "two"} {
+			target := target
+			q.Submit(target, target, func() {
+				started <- target
+				<-release
+			})
+		}
+		assert.ElementsMatch(t, []string{"one", "two"}, []string{<-started, <-started})
+
+		// a command that is already queued or running is not queued again
+		assert.False(t, q.Submit("one", "one", func() { t.Error("ran twice") }))
+		close(release)
+	})
+}
+
+// slowDeployments makes patches of deployments block until their context is done.
+func slowDeployments() appsv1.DeploymentsGetter {
+	clientset := fake.NewSimpleClientset(&apiappsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "quote", Namespace: "default"},
+	})
+	return &contextAwareDeployments{clientset.AppsV1()}
+}
+
+type contextAwareDeployments struct {
+	appsv1.DeploymentsGetter
+}
+
+type contextAwareDeployment struct {
+	appsv1.DeploymentInterface
+}
+
+func (c *contextAwareDeployments) Deployments(namespace string) appsv1.DeploymentInterface {
+	return &contextAwareDeployment{c.DeploymentsGetter.Deployments(namespace)}
+}
+
+func (c *contextAwareDeployment) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*apiappsv1.Deployment, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestRunCommandInBackground(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	client := &MockClient{}
+	a := newTestAgent(client)
+	a.Env = &Env{CommandTimeouts: map[commandKind]time.Duration{commandKindDeployment: 10 * time.Millisecond}}
+	a.commandQueue = newCommandQueue(2)
+	a.completedCommands = make(chan *agent.CommandResult)
+	dh := &BasicDirectiveHandler{
+		deploymentsGetterFactory: func() (appsv1.DeploymentsGetter, error) { return slowDeployments(), nil },
+	}
+
+	// HandleDirective returns right away while the command runs
+	dh.HandleDirective(ctx, a, &agent.Directive{ID: "one", Commands: []*agent.Command{
+		{DeploymentCommand: &agent.DeploymentCommand{
+			CommandId: "restart",
+			Name:      "quote",
+			Namespace: "default",
+			Action:    agent.DeploymentCommand_RESTART,
+		}},
+	}})
+	assert.Empty(t, client.GetResults())
+
+	select {
+	case result := <-a.completedCommands:
+		assert.Equal(t, "restart", result.CommandId)
+		assert.False(t, result.Success)
+		assert.Equal(t, agent.CommandResult_TIMEOUT, result.Status)
+		assert.Contains(t, result.Message, "timed out after 10ms")
+	case <-time.After(5 * time.Second):
+		t.Fatal("command did not time out")
+	}
+}
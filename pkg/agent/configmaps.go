@@ -0,0 +1,204 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	apiv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+type configMapSyncAction string
+
+const (
+	configMapSyncActionSet     = configMapSyncAction("SET")
+	configMapSyncActionDelete  = configMapSyncAction("DELETE")
+	configMapSyncActionReplace = configMapSyncAction("REPLACE")
+)
+
+// ConfigMapInterface describes the operations used to manage ConfigMaps in Kubernetes.
+type ConfigMapInterface interface {
+	Create(ctx context.Context, configMap *apiv1.ConfigMap, opts metav1.CreateOptions) (*apiv1.ConfigMap, error)
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*apiv1.ConfigMap, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (result *apiv1.ConfigMap, err error)
+	Update(ctx context.Context, configMap *apiv1.ConfigMap, opts metav1.UpdateOptions) (*apiv1.ConfigMap, error)
+}
+
+// configMapsGetterFactory is a factory for creating ConfigMapInterface.
+type configMapsGetterFactory func(namespace string) (ConfigMapInterface, error)
+
+// configMapSyncCommand is the counterpart of secretSyncCommand for non-sensitive configuration.
+type configMapSyncCommand struct {
+	name       string
+	namespace  string
+	action     configMapSyncAction
+	data       map[string]string
+	binaryData map[string][]byte
+	dryRun     bool
+
+	labels      map[string]string
+	annotations map[string]string
+
+	// allowUnowned allows changing ConfigMaps that weren't created by the agent.
+	allowUnowned bool
+
+	// outcome says what happened to the ConfigMap, once the command has run.
+	outcome string
+}
+
+func (c *configMapSyncCommand) String() string {
+	return fmt.Sprintf("<configmap=%s namespace=%s action=%s>", c.name, c.namespace, c.action)
+}
+
+// DryRun tells whether the command is only validated by the API server, without changing anything.
+func (c *configMapSyncCommand) DryRun() bool {
+	return c.dryRun
+}
+
+// DryRunMessage describes what the command would have done to the ConfigMap.
+func (c *configMapSyncCommand) DryRunMessage() string {
+	return fmt.Sprintf("dry run, configmap %s (%s) would be %s", c.name, c.namespace, c.outcome)
+}
+
+func (c *configMapSyncCommand) RunWithClientFactory(
+	ctx context.Context, configMapsGetterFactory configMapsGetterFactory,
+) error {
+	client, err := configMapsGetterFactory(c.namespace)
+	if err != nil {
+		return err
+	}
+
+	return c.syncConfigMap(ctx, client)
+}
+
+func (c *configMapSyncCommand) syncConfigMap(ctx context.Context, client ConfigMapInterface) error {
+	configMap, err := client.Get(ctx, c.name, metav1.GetOptions{})
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get the configmap %s: %w", c.name, err)
+		}
+		if c.action != configMapSyncActionSet && c.action != configMapSyncActionReplace {
+			c.outcome = "left alone, it does not exist"
+			return nil
+		}
+		_, err = client.Create(ctx, &apiv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        c.name,
+				Namespace:   c.namespace,
+				Labels:      ownedLabels(c.labels),
+				Annotations: c.annotations,
+			},
+			Data:       c.data,
+			BinaryData: c.binaryData,
+		}, metav1.CreateOptions{DryRun: dryRunOption(c.dryRun)})
+		if err != nil {
+			return fmt.Errorf("failed to create the configmap: %w", err)
+		}
+		c.outcome = "created"
+		return nil
+	}
+
+	if !c.allowUnowned && !ownedByAgent(&configMap.ObjectMeta) {
+		return fmt.Errorf("configmap %s (%s) was not created by the agent, refusing to change it", c.name, c.namespace)
+	}
+
+	var ops []map[string]interface{}
+	switch c.action {
+	case configMapSyncActionSet:
+		ops = getOps("/data", c.data, false, len(configMap.Data) == 0 && len(c.data) > 0)
+		ops = append(ops, getOps("/binaryData", encodeValues(c.binaryData), false,
+			len(configMap.BinaryData) == 0 && len(c.binaryData) > 0)...)
+		ops = append(ops, metadataOps(&configMap.ObjectMeta, c.labels, c.annotations, false)...)
+	case configMapSyncActionDelete:
+		// The keys may be in either field, and removing a key that isn't there fails
+		ops = getOps("/data", presentKeys(c.data, c.binaryData, configMap.Data), true, false)
+		ops = append(ops, getOps("/binaryData", presentKeys(c.data, c.binaryData, configMap.BinaryData), true, false)...)
+	case configMapSyncActionReplace:
+		return c.replaceConfigMap(ctx, client, configMap)
+	default:
+		return fmt.Errorf(
+			"failed to generate required update operations: action %s is not supported by the configmap sync directive", c.action,
+		)
+	}
+
+	opsJSON, err := json.Marshal(ops)
+	if err != nil {
+		return fmt.Errorf("failed to generate patch ops: %w", err)
+	}
+
+	configMap, err = client.Patch(ctx, c.name, types.JSONPatchType, opsJSON, metav1.PatchOptions{DryRun: dryRunOption(c.dryRun)})
+	if err != nil {
+		return fmt.Errorf("failed to update the configmap: %w", err)
+	}
+	c.outcome = "updated"
+
+	// if no keys left, we should delete the configmap.
+	if len(configMap.Data) == 0 && len(configMap.BinaryData) == 0 {
+		err := client.Delete(ctx, c.name, metav1.DeleteOptions{DryRun: dryRunOption(c.dryRun)})
+		if err != nil {
+			return fmt.Errorf("failed to clean up the configmap %s: %w", c.name, err)
+		}
+		c.outcome = "deleted"
+	}
+
+	return nil
+}
+
+// replaceConfigMap makes the keys of the ConfigMap exactly those of the command. It
+// fails rather than overwrite the changes made since the ConfigMap was read.
+func (c *configMapSyncCommand) replaceConfigMap(ctx context.Context, client ConfigMapInterface, configMap *apiv1.ConfigMap) error {
+	configMap = configMap.DeepCopy()
+	configMap.Data = c.data
+	configMap.BinaryData = c.binaryData
+	mergeMetadata(&configMap.ObjectMeta, c.labels, c.annotations, false)
+
+	// The update is rejected with a conflict when the resourceVersion read above is no longer current
+	configMap, err := client.Update(ctx, configMap, metav1.UpdateOptions{DryRun: dryRunOption(c.dryRun)})
+	if err != nil {
+		return fmt.Errorf("failed to replace the configmap %s: %w", c.name, err)
+	}
+	c.outcome = "replaced"
+
+	// if no keys left, we should delete the configmap, as SET and DELETE do.
+	if len(configMap.Data) == 0 && len(configMap.BinaryData) == 0 {
+		err := client.Delete(ctx, c.name, metav1.DeleteOptions{DryRun: dryRunOption(c.dryRun)})
+		if err != nil {
+			return fmt.Errorf("failed to clean up the configmap %s: %w", c.name, err)
+		}
+		c.outcome = "deleted"
+	}
+	return nil
+}
+
+// presentKeys returns the keys of data and binaryData that are in existing.
+func presentKeys[T any](data map[string]string, binaryData map[string][]byte, existing map[string]T) map[string]string {
+	keys := make(map[string]string)
+	for key := range existing {
+		_, inData := data[key]
+		_, inBinaryData := binaryData[key]
+		if inData || inBinaryData {
+			keys[key] = ""
+		}
+	}
+	return keys
+}
+
+// NewConfigMapsGetter instantiates a client to interact with the Kubernetes ConfigMap API.
+func NewConfigMapsGetter(namespace string) (ConfigMapInterface, error) {
+	kubeConfig, err := newK8sRestClient()
+	if err != nil {
+		return nil, err
+	}
+
+	clientSet, err := kubernetes.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return clientSet.CoreV1().ConfigMaps(namespace), nil
+}
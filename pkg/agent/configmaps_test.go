@@ -0,0 +1,261 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/datawire/ambassador-agent/pkg/api/agent"
+	"github.com/datawire/dlib/dlog"
+)
+
+func newConfigMapsClient() *fake.Clientset {
+	return fake.NewSimpleClientset(
+		&apiv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "quote-config",
+				Namespace: "ambassador",
+				Labels:    map[string]string{managedByLabel: managedByAgent},
+			},
+			Data:       map[string]string{"greeting": "hello"},
+			BinaryData: map[string][]byte{"logo.png": {0x89, 0x50}},
+		},
+		&apiv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "kube-root-ca.crt", Namespace: "ambassador"},
+			Data:       map[string]string{"ca.crt": "cert"},
+		},
+	)
+}
+
+func TestConfigMapSyncCommand_RunWithClientFactory(t *testing.T) {
+	type testcase struct {
+		cmd       configMapSyncCommand
+		check     func(t *testing.T, configMap *apiv1.ConfigMap)
+		expectErr string
+	}
+	cases := map[string]testcase{
+		"create": {
+			cmd: configMapSyncCommand{
+				name:       "new-config",
+				action:     configMapSyncActionSet,
+				data:       map[string]string{"greeting": "hi"},
+				binaryData: map[string][]byte{"blob": {1, 2}},
+				labels:     map[string]string{"app.kubernetes.io/name": "quote"},
+			},
+			check: func(t *testing.T, configMap *apiv1.ConfigMap) {
+				assert.Equal(t, map[string]string{"greeting": "hi"}, configMap.Data)
+				assert.Equal(t, map[string][]byte{"blob": {1, 2}}, configMap.BinaryData)
+				assert.Equal(t, map[string]string{"app.kubernetes.io/name": "quote", managedByLabel: managedByAgent}, configMap.Labels)
+			},
+		},
+		"set keys": {
+			cmd: configMapSyncCommand{
+				name:        "quote-config",
+				action:      configMapSyncActionSet,
+				data:        map[string]string{"farewell": "bye"},
+				binaryData:  map[string][]byte{"icon.png": {0x47}},
+				annotations: map[string]string{"owner": "team-a"},
+			},
+			check: func(t *testing.T, configMap *apiv1.ConfigMap) {
+				assert.Equal(t, map[string]string{"greeting": "hello", "farewell": "bye"}, configMap.Data)
+				assert.Equal(t, map[string][]byte{"logo.png": {0x89, 0x50}, "icon.png": {0x47}}, configMap.BinaryData)
+				assert.Equal(t, map[string]string{"owner": "team-a"}, configMap.Annotations)
+			},
+		},
+		"delete keys": {
+			cmd: configMapSyncCommand{
+				name:   "quote-config",
+				action: configMapSyncActionDelete,
+				data:   map[string]string{"logo.png": "", "unknown": ""},
+			},
+			check: func(t *testing.T, configMap *apiv1.ConfigMap) {
+				assert.Equal(t, map[string]string{"greeting": "hello"}, configMap.Data)
+				assert.Empty(t, configMap.BinaryData)
+			},
+		},
+		"replace keys": {
+			cmd: configMapSyncCommand{
+				name:   "quote-config",
+				action: configMapSyncActionReplace,
+				data:   map[string]string{"farewell": "bye"},
+			},
+			check: func(t *testing.T, configMap *apiv1.ConfigMap) {
+				assert.Equal(t, map[string]string{"farewell": "bye"}, configMap.Data)
+				assert.Empty(t, configMap.BinaryData)
+				assert.Equal(t, managedByAgent, configMap.Labels[managedByLabel])
+			},
+		},
+		"not owned": {
+			cmd: configMapSyncCommand{
+				name:   "kube-root-ca.crt",
+				action: configMapSyncActionDelete,
+				data:   map[string]string{"ca.crt": ""},
+			},
+			expectErr: "configmap kube-root-ca.crt (ambassador) was not created by the agent, refusing to change it",
+		},
+		"not owned, but allowed": {
+			cmd: configMapSyncCommand{
+				name:         "kube-root-ca.crt",
+				action:       configMapSyncActionSet,
+				data:         map[string]string{"extra.crt": "cert"},
+				allowUnowned: true,
+			},
+			check: func(t *testing.T, configMap *apiv1.ConfigMap) {
+				assert.Equal(t, map[string]string{"ca.crt": "cert", "extra.crt": "cert"}, configMap.Data)
+				assert.NotContains(t, configMap.Labels, managedByLabel)
+			},
+		},
+	}
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			client := newConfigMapsClient()
+			cmd := c.cmd
+			cmd.namespace = "ambassador"
+
+			err := cmd.RunWithClientFactory(ctx, func(namespace string) (ConfigMapInterface, error) {
+				return client.CoreV1().ConfigMaps(namespace), nil
+			})
+			if c.expectErr != "" {
+				assert.EqualError(t, err, c.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			configMap, err := client.CoreV1().ConfigMaps("ambassador").Get(ctx, cmd.name, metav1.GetOptions{})
+			require.NoError(t, err)
+			c.check(t, configMap)
+		})
+	}
+
+	t.Run("delete the last keys", func(t *testing.T) {
+		ctx := context.Background()
+		client := newConfigMapsClient()
+		cmd := &configMapSyncCommand{
+			name:       "quote-config",
+			namespace:  "ambassador",
+			action:     configMapSyncActionDelete,
+			data:       map[string]string{"greeting": ""},
+			binaryData: map[string][]byte{"logo.png": nil},
+		}
+
+		err := cmd.RunWithClientFactory(ctx, func(namespace string) (ConfigMapInterface, error) {
+			return client.CoreV1().ConfigMaps(namespace), nil
+		})
+		require.NoError(t, err)
+		_, err = client.CoreV1().ConfigMaps("ambassador").Get(ctx, "quote-config", metav1.GetOptions{})
+		assert.True(t, k8serrors.IsNotFound(err), "configmap deleted")
+		assert.Equal(t, "deleted", cmd.outcome)
+	})
+
+	t.Run("replace with no keys", func(t *testing.T) {
+		ctx := context.Background()
+		client := newConfigMapsClient()
+		cmd := &configMapSyncCommand{
+			name:      "quote-config",
+			namespace: "ambassador",
+			action:    configMapSyncActionReplace,
+		}
+
+		err := cmd.RunWithClientFactory(ctx, func(namespace string) (ConfigMapInterface, error) {
+			return client.CoreV1().ConfigMaps(namespace), nil
+		})
+		require.NoError(t, err)
+		_, err = client.CoreV1().ConfigMaps("ambassador").Get(ctx, "quote-config", metav1.GetOptions{})
+		assert.True(t, k8serrors.IsNotFound(err), "configmap deleted")
+		assert.Equal(t, "deleted", cmd.outcome)
+	})
+
+	t.Run("replace with no keys, dry run", func(t *testing.T) {
+		ctx := context.Background()
+		client := newConfigMapsClient()
+		cmd := &configMapSyncCommand{
+			name:       "quote-config",
+			namespace:  "ambassador",
+			action:     configMapSyncActionReplace,
+			data:       map[string]string{},
+			binaryData: map[string][]byte{},
+			dryRun:     true,
+		}
+
+		err := cmd.RunWithClientFactory(ctx, func(namespace string) (ConfigMapInterface, error) {
+			return client.CoreV1().ConfigMaps(namespace), nil
+		})
+		require.NoError(t, err)
+		var deleted bool
+		for _, action := range client.Actions() {
+			if action.GetVerb() == "delete" {
+				deleted = true
+				assert.Equal(t, []string{metav1.DryRunAll}, action.(k8stesting.DeleteAction).GetDeleteOptions().DryRun)
+			}
+		}
+		assert.True(t, deleted, "configmap deleted in the dry run")
+		assert.Equal(t, "dry run, configmap quote-config (ambassador) would be deleted", cmd.DryRunMessage())
+	})
+}
+
+func TestHandleConfigMapSyncDirective(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	client := &MockClient{}
+	a := newTestAgent(client)
+	a.Env = &Env{AgentNamespace: "ambassador", CommandCacheConfigMap: "agent-commands"}
+	clientset := newConfigMapsClient()
+	_, err := clientset.CoreV1().ConfigMaps("ambassador").Create(ctx, &apiv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent-commands", Labels: ownedLabels(nil)},
+		Data:       map[string]string{commandCacheKey: "[]"},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	dh := &BasicDirectiveHandler{
+		configMapsGetterFactory: func(namespace string) (ConfigMapInterface, error) {
+			return clientset.CoreV1().ConfigMaps(namespace), nil
+		},
+	}
+
+	dh.HandleDirective(ctx, a, &agent.Directive{ID: "one", Commands: []*agent.Command{
+		{ConfigMapSyncCommand: &agent.ConfigMapSyncCommand{
+			CommandId: "set",
+			Name:      "quote-config",
+			Namespace: "ambassador",
+			Action:    agent.ConfigMapSyncCommand_SET,
+			Data:      map[string]string{"farewell": "bye"},
+		}},
+		{ConfigMapSyncCommand: &agent.ConfigMapSyncCommand{
+			CommandId: "replace",
+			Name:      "kube-root-ca.crt",
+			Namespace: "ambassador",
+			Action:    agent.ConfigMapSyncCommand_REPLACE,
+		}},
+		{ConfigMapSyncCommand: &agent.ConfigMapSyncCommand{
+			CommandId: "command cache",
+			Name:      "agent-commands",
+			Namespace: "ambassador",
+			Action:    agent.ConfigMapSyncCommand_REPLACE,
+		}},
+	}})
+
+	results := client.GetResults()
+	require.Len(t, results, 3)
+	assert.Equal(t, "set", results[0].CommandId)
+	assert.True(t, results[0].Success)
+	assert.Equal(t, "replace", results[1].CommandId)
+	assert.False(t, results[1].Success)
+	assert.Contains(t, results[1].Message, "was not created by the agent")
+	assert.Equal(t, "command cache", results[2].CommandId)
+	assert.False(t, results[2].Success)
+
+	_, err = clientset.CoreV1().ConfigMaps("ambassador").Get(ctx, "agent-commands", metav1.GetOptions{})
+	require.NoError(t, err, "command cache left alone")
+	configMap, err := clientset.CoreV1().ConfigMaps("ambassador").Get(ctx, "quote-config", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "bye", configMap.Data["farewell"])
+}
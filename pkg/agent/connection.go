@@ -0,0 +1,79 @@
+package agent
+
+import (
+	"math/rand"
+	"time"
+)
+
+// ConnState is the state of the connection to the Director.
+type ConnState int32
+
+const (
+	// ConnStateConnecting means that no RPC has completed on the connection yet.
+	ConnStateConnecting ConnState = iota
+	// ConnStateReady means that the last RPC to the Director succeeded.
+	ConnStateReady
+	// ConnStateDegraded means that the last RPC to the Director failed and that
+	// the connection is retried with backoff.
+	ConnStateDegraded
+	// ConnStateAuthFailed means that the Director rejected the CLOUD_CONNECT_TOKEN.
+	// Nothing is reported until the connection recovers.
+	ConnStateAuthFailed
+)
+
+func (s ConnState) String() string {
+	switch s {
+	case ConnStateConnecting:
+		return "connecting"
+	case ConnStateReady:
+		return "ready"
+	case ConnStateDegraded:
+		return "degraded"
+	case ConnStateAuthFailed:
+		return "auth-failed"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	// backoffInitial and backoffMax bound the delay between two attempts to
+	// reach the Director.
+	backoffInitial = 1 * time.Second
+	backoffMax     = 2 * time.Minute
+	// backoffJitter is the fraction by which each delay is randomly spread.
+	backoffJitter = 0.2
+	// authFailedRetryDelay is the minimum delay before trying again with a
+	// token that the Director rejected.
+	authFailedRetryDelay = 5 * time.Minute
+
+	// keepaliveTime and keepaliveTimeout configure the gRPC keepalive pings
+	// sent to the Director. keepaliveTime stays at the gRPC server's default
+	// enforcement minimum so that the Director never considers the pings abusive.
+	keepaliveTime    = 5 * time.Minute
+	keepaliveTimeout = 20 * time.Second
+)
+
+// backoff computes jittered exponential delays. The zero value is ready to use.
+type backoff struct {
+	current time.Duration
+}
+
+// Next returns the delay to wait before the next attempt.
+func (b *backoff) Next() time.Duration {
+	switch {
+	case b.current == 0:
+		b.current = backoffInitial
+	case b.current < backoffMax/2:
+		b.current *= 2
+	default:
+		b.current = backoffMax
+	}
+	spread := (2*rand.Float64() - 1) * backoffJitter //nolint:gosec // jitter doesn't need a secure source
+	return time.Duration(float64(b.current) * (1 + spread))
+}
+
+// Reset makes the next delay the initial one again.
+func (b *backoff) Reset() {
+	b.current = 0
+}
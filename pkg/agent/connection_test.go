@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/datawire/ambassador-agent/pkg/api/agent"
+	"github.com/datawire/dlib/dlog"
+)
+
+func TestBackoff(t *testing.T) {
+	var b backoff
+	expected := backoffInitial
+	for i := 0; i < 20; i++ {
+		delay := b.Next()
+		assert.GreaterOrEqual(t, delay, time.Duration(float64(expected)*(1-backoffJitter)))
+		assert.LessOrEqual(t, delay, time.Duration(float64(expected)*(1+backoffJitter)))
+		if expected *= 2; expected > backoffMax {
+			expected = backoffMax
+		}
+	}
+
+	b.Reset()
+	assert.LessOrEqual(t, b.Next(), time.Duration(float64(backoffInitial)*(1+backoffJitter)))
+}
+
+func TestCommState(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	c := &RPCComm{rptWake: make(chan struct{}, 1)}
+	assert.Equal(t, ConnStateConnecting, c.State())
+
+	c.observe(ctx, status.Error(codes.Unavailable, "connection refused"))
+	assert.Equal(t, ConnStateDegraded, c.State())
+	assert.Less(t, c.retryDelay(), authFailedRetryDelay)
+
+	c.observe(ctx, nil)
+	assert.Equal(t, ConnStateReady, c.State())
+	// the retriever is woken up as soon as the Director can be reached again
+	assert.Len(t, c.rptWake, 1)
+
+	c.observe(ctx, status.Error(codes.Unauthenticated, "invalid api key"))
+	assert.Equal(t, ConnStateAuthFailed, c.State())
+	assert.GreaterOrEqual(t, c.retryDelay(), authFailedRetryDelay)
+
+	// errors that didn't come from the Director leave the state alone
+	c.observe(ctx, context.DeadlineExceeded)
+	assert.Equal(t, ConnStateAuthFailed, c.State())
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	c.observe(cctx, status.Error(codes.Canceled, "context canceled"))
+	assert.Equal(t, ConnStateAuthFailed, c.State())
+}
+
+type unauthenticatedDirectorClient struct {
+	agent.DirectorClient
+	retrieves chan struct{}
+}
+
+func (m *unauthenticatedDirectorClient) Retrieve(context.Context, *agent.Identity, ...grpc.CallOption) (agent.Director_RetrieveClient, error) {
+	m.retrieves <- struct{}{}
+	return nil, status.Error(codes.Unauthenticated, "invalid api key")
+}
+
+func TestRetrieveLoopAuthFailed(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	client := &unauthenticatedDirectorClient{retrieves: make(chan struct{}, 10)}
+	c := &RPCComm{
+		client:     client,
+		rptWake:    make(chan struct{}, 1),
+		retCancel:  cancel,
+		agentID:    &agent.Identity{},
+		directives: make(chan *agent.Directive, 1),
+	}
+	go c.retrieveLoop(ctx)
+
+	<-client.retrieves
+	require.Eventually(t, func() bool {
+		return c.State() == ConnStateAuthFailed
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the rejected token isn't retried right away
+	select {
+	case <-client.retrieves:
+		t.Fatal("Retrieve retried with a rejected token")
+	case <-time.After(2 * backoffInitial):
+	}
+}
@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// contentEncoding is a compression applied to raw snapshots and diagnostics.
+// The empty contentEncoding leaves the content as is.
+type contentEncoding string
+
+const (
+	contentEncodingNone = contentEncoding("")
+	contentEncodingGzip = contentEncoding("gzip")
+	contentEncodingZstd = contentEncoding("zstd")
+)
+
+// encodedContentType returns the content type advertised for content of the
+// given type once it has been encoded, e.g. "application/json+gzip".
+func encodedContentType(contentType string, encoding contentEncoding) string {
+	if encoding == contentEncodingNone {
+		return contentType
+	}
+	return contentType + "+" + string(encoding)
+}
+
+// negotiateContentEncoding picks the first of the content types accepted by
+// the Director that is an encoding of contentType that the agent supports.
+func negotiateContentEncoding(contentType string, accepted []string) contentEncoding {
+	for _, ct := range accepted {
+		if ct == contentType {
+			return contentEncodingNone
+		}
+		encoding, ok := strings.CutPrefix(ct, contentType+"+")
+		if !ok {
+			continue
+		}
+		switch e := contentEncoding(encoding); e {
+		case contentEncodingGzip, contentEncodingZstd:
+			return e
+		}
+	}
+	return contentEncodingNone
+}
+
+// encodeContent compresses data using the given encoding.
+func encodeContent(encoding contentEncoding, data []byte) ([]byte, error) {
+	switch encoding {
+	case contentEncodingNone:
+		return data, nil
+	case contentEncodingGzip:
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(data); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case contentEncodingZstd:
+		zw, err := zstd.NewWriter(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer zw.Close()
+		return zw.EncodeAll(data, make([]byte, 0, len(data)/4)), nil
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+}
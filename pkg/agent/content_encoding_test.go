@@ -0,0 +1,142 @@
+package agent
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/datawire/ambassador-agent/pkg/api/agent"
+	"github.com/datawire/dlib/dlog"
+)
+
+// decodeContent stands in for the Director, decoding raw content according to
+// its content type.
+func decodeContent(contentType string, data []byte) ([]byte, error) {
+	switch {
+	case strings.HasSuffix(contentType, "+gzip"):
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return io.ReadAll(zr)
+	case strings.HasSuffix(contentType, "+zstd"):
+		zr, err := zstd.NewReader(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return zr.DecodeAll(data, nil)
+	default:
+		return data, nil
+	}
+}
+
+// newLargeRawContent returns JSON that remains larger than a single chunk
+// once compressed.
+func newLargeRawContent(t *testing.T) []byte {
+	noise := make([]byte, 3*chunkSize)
+	_, _ = rand.New(rand.NewSource(1)).Read(noise)
+	raw, err := json.Marshal(map[string]string{"noise": hex.EncodeToString(noise)})
+	require.NoError(t, err)
+	return raw
+}
+
+func TestNegotiateContentEncoding(t *testing.T) {
+	type testcase struct {
+		accepted []string
+		expected contentEncoding
+	}
+	cases := map[string]testcase{
+		"nothing accepted": {
+			expected: contentEncodingNone,
+		},
+		"zstd preferred": {
+			accepted: []string{"application/json+zstd", "application/json+gzip"},
+			expected: contentEncodingZstd,
+		},
+		"gzip preferred": {
+			accepted: []string{"application/json+gzip", "application/json+zstd"},
+			expected: contentEncodingGzip,
+		},
+		"plain json preferred": {
+			accepted: []string{"application/json", "application/json+gzip"},
+			expected: contentEncodingNone,
+		},
+		"unsupported encodings skipped": {
+			accepted: []string{"application/json+br", "application/yaml+gzip", "application/json+gzip"},
+			expected: contentEncodingGzip,
+		},
+		"nothing supported": {
+			accepted: []string{"application/json+br"},
+			expected: contentEncodingNone,
+		},
+	}
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, c.expected, negotiateContentEncoding("application/json", c.accepted))
+		})
+	}
+}
+
+func TestReportContentEncoding(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	raw := newLargeRawContent(t)
+	for _, encoding := range []contentEncoding{contentEncodingNone, contentEncodingGzip, contentEncodingZstd} {
+		encoding := encoding
+		t.Run(string(encoding), func(t *testing.T) {
+			client := &MockClient{}
+			a := newTestAgent(client)
+
+			report := &agent.Snapshot{
+				Identity:    &agent.Identity{ClusterId: "cluster"},
+				RawSnapshot: raw,
+				ContentType: "application/json",
+				SnapshotTs:  timestamppb.Now(),
+			}
+			require.NoError(t, a.sendReport(ctx, report, encoding, "apikey"))
+			diagnostics := &agent.Diagnostics{
+				Identity:       &agent.Identity{ClusterId: "cluster"},
+				RawDiagnostics: raw,
+				ContentType:    "application/json",
+				SnapshotTs:     timestamppb.Now(),
+			}
+			require.NoError(t, a.sendDiagnostics(ctx, diagnostics, encoding, "apikey"))
+
+			// the reports handed to the agent are left untouched
+			assert.Equal(t, "application/json", report.ContentType)
+			assert.Equal(t, raw, report.RawSnapshot)
+			assert.Equal(t, "application/json", diagnostics.ContentType)
+			assert.Equal(t, raw, diagnostics.RawDiagnostics)
+
+			snapshots := client.GetSnapshots()
+			require.Len(t, snapshots, 1)
+			assert.Equal(t, encodedContentType("application/json", encoding), snapshots[0].ContentType)
+			if encoding != contentEncodingNone {
+				assert.Less(t, len(snapshots[0].RawSnapshot), len(raw))
+				assert.Greater(t, chunkCount(snapshots[0].RawSnapshot), 1)
+			}
+			decoded, err := decodeContent(snapshots[0].ContentType, snapshots[0].RawSnapshot)
+			require.NoError(t, err)
+			assert.Equal(t, raw, decoded)
+
+			sentDiagnostics := client.GetDiagnostics()
+			require.Len(t, sentDiagnostics, 1)
+			assert.Equal(t, encodedContentType("application/json", encoding), sentDiagnostics[0].ContentType)
+			decoded, err = decodeContent(sentDiagnostics[0].ContentType, sentDiagnostics[0].RawDiagnostics)
+			require.NoError(t, err)
+			assert.Equal(t, raw, decoded)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	appsv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+)
+
+// deploymentAction indicates the action to be performed on a Deployment.
+type deploymentAction string
+
+const (
+	// deploymentActionRestart represents the "rollout restart" action on a Deployment.
+	deploymentActionRestart = deploymentAction("RESTART")
+	// deploymentActionScale represents the "scale" action on a Deployment.
+	deploymentActionScale = deploymentAction("SCALE")
+)
+
+// restartedAtAnnotation is the pod template annotation bumped by kubectl rollout restart.
+const restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// deploymentsGetterFactory is a factory for creating DeploymentsGetter.
+type deploymentsGetterFactory func() (appsv1.DeploymentsGetter, error)
+
+// deploymentCommand holds a reference to a Deployment command to be ran.
+type deploymentCommand struct {
+	namespace      string
+	deploymentName string
+	action         deploymentAction
+	replicas       int32
+	dryRun         bool
+}
+
+func (d *deploymentCommand) String() string {
+	if d.action == deploymentActionScale {
+		return fmt.Sprintf("<deployment=%s namespace=%s action=%s replicas=%d>", d.deploymentName, d.namespace, d.action, d.replicas)
+	}
+	return fmt.Sprintf("<deployment=%s namespace=%s action=%s>", d.deploymentName, d.namespace, d.action)
+}
+
+// DryRun tells whether the command is only validated by the API server, without changing anything.
+func (d *deploymentCommand) DryRun() bool {
+	return d.dryRun
+}
+
+// RunWithClientFactory runs the given Deployment command using deploymentsClientFactory to get a DeploymentsGetter.
+func (d *deploymentCommand) RunWithClientFactory(ctx context.Context, deploymentsClientFactory deploymentsGetterFactory) error {
+	client, err := deploymentsClientFactory()
+	if err != nil {
+		return err
+	}
+	return d.patchDeployment(ctx, client)
+}
+
+func (d *deploymentCommand) patchDeployment(ctx context.Context, client appsv1.DeploymentsGetter) error {
+	var patch map[string]any
+	switch d.action {
+	case deploymentActionRestart:
+		// Same as kubectl rollout restart: changing the pod template rolls out new pods
+		patch = map[string]any{"spec": map[string]any{"template": map[string]any{"metadata": map[string]any{
+			"annotations": map[string]any{restartedAtAnnotation: time.Now().Format(time.RFC3339)},
+		}}}}
+	case deploymentActionScale:
+		if d.replicas < 0 {
+			return fmt.Errorf("cannot scale deployment %s (%s) to %d replicas", d.deploymentName, d.namespace, d.replicas)
+		}
+		patch = map[string]any{"spec": map[string]any{"replicas": d.replicas}}
+	default:
+		return fmt.Errorf(
+			"tried to perform unknown action '%s' on deployment %s (%s)",
+			d.action,
+			d.deploymentName,
+			d.namespace,
+		)
+	}
+
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	_, err = client.Deployments(d.namespace).Patch(ctx, d.deploymentName, types.MergePatchType, data, metav1.PatchOptions{
+		DryRun: dryRunOption(d.dryRun),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to %s deployment %s (%s): %w", d.action, d.deploymentName, d.namespace, err)
+	}
+	return nil
+}
+
+// NewDeploymentsGetter creates a DeploymentsGetter from the apps/v1 API.
+func NewDeploymentsGetter() (appsv1.DeploymentsGetter, error) {
+	kubeConfig, err := newK8sRestClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return appsv1.NewForConfig(kubeConfig)
+}
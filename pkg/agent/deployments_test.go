@@ -0,0 +1,152 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiappsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	appsv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+
+	"github.com/datawire/ambassador-agent/pkg/api/agent"
+	"github.com/datawire/dlib/dlog"
+)
+
+func newFakeDeploymentsGetter(replicas int32) appsv1.DeploymentsGetter {
+	return fake.NewSimpleClientset(&apiappsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "quote", Namespace: "default"},
+		Spec:       apiappsv1.DeploymentSpec{Replicas: &replicas},
+	}).AppsV1()
+}
+
+func TestDeploymentCommand_RunWithClient(t *testing.T) {
+	ctx := context.Background()
+	type testcase struct {
+		action   deploymentAction
+		replicas int32
+
+		expectedReplicas int32
+		restarted        bool
+		expectErr        bool
+	}
+	cases := map[string]testcase{
+		"scale up": {
+			action:           deploymentActionScale,
+			replicas:         3,
+			expectedReplicas: 3,
+		},
+		"scale to zero": {
+			action:           deploymentActionScale,
+			expectedReplicas: 0,
+		},
+		"negative replicas": {
+			action:           deploymentActionScale,
+			replicas:         -1,
+			expectedReplicas: 1,
+			expectErr:        true,
+		},
+		"restart": {
+			action:           deploymentActionRestart,
+			expectedReplicas: 1,
+			restarted:        true,
+		},
+		"unknown action": {
+			action:           deploymentAction("DELETE"),
+			expectedReplicas: 1,
+			expectErr:        true,
+		},
+	}
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			client := newFakeDeploymentsGetter(1)
+			cmd := &deploymentCommand{
+				namespace:      "default",
+				deploymentName: "quote",
+				action:         c.action,
+				replicas:       c.replicas,
+			}
+			err := cmd.RunWithClientFactory(ctx, func() (appsv1.DeploymentsGetter, error) { return client, nil })
+			if c.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			deployment, err := client.Deployments("default").Get(ctx, "quote", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, c.expectedReplicas, *deployment.Spec.Replicas)
+			restartedAt, ok := deployment.Spec.Template.Annotations[restartedAtAnnotation]
+			assert.Equal(t, c.restarted, ok)
+			if ok {
+				_, err = time.Parse(time.RFC3339, restartedAt)
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("deployment not found", func(t *testing.T) {
+		cmd := &deploymentCommand{namespace: "other", deploymentName: "quote", action: deploymentActionRestart}
+		err := cmd.RunWithClientFactory(ctx, func() (appsv1.DeploymentsGetter, error) { return newFakeDeploymentsGetter(1), nil })
+		assert.Error(t, err)
+	})
+}
+
+func TestHandleDeploymentDirective(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	client := &MockClient{}
+	a := newTestAgent(client)
+	deployments := newFakeDeploymentsGetter(1)
+	dh := &BasicDirectiveHandler{
+		deploymentsGetterFactory: func() (appsv1.DeploymentsGetter, error) { return deployments, nil },
+	}
+
+	dh.HandleDirective(ctx, a, &agent.Directive{ID: "one", Commands: []*agent.Command{
+		{DeploymentCommand: &agent.DeploymentCommand{
+			CommandId: "dry run",
+			Name:      "quote",
+			Namespace: "default",
+			Action:    agent.DeploymentCommand_SCALE,
+			Replicas:  5,
+			DryRun:    true,
+		}},
+		{DeploymentCommand: &agent.DeploymentCommand{
+			CommandId: "scale",
+			Name:      "quote",
+			Namespace: "default",
+			Action:    agent.DeploymentCommand_SCALE,
+			Replicas:  2,
+		}},
+		{DeploymentCommand: &agent.DeploymentCommand{
+			CommandId: "missing namespace",
+			Name:      "quote",
+		}},
+		{DeploymentCommand: &agent.DeploymentCommand{
+			CommandId: "missing action",
+			Name:      "quote",
+			Namespace: "default",
+		}},
+	}})
+
+	results := client.GetResults()
+	require.Len(t, results, 3)
+	assert.Equal(t, "dry run", results[0].CommandId)
+	assert.True(t, results[0].DryRun)
+	assert.Equal(t, "scale", results[1].CommandId)
+	assert.True(t, results[1].Success)
+	assert.False(t, results[1].DryRun)
+	assert.Equal(t, "missing action", results[2].CommandId)
+	assert.Equal(t, agent.CommandResult_FAILED, results[2].Status)
+
+	deployment, err := deployments.Deployments("default").Get(ctx, "quote", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), *deployment.Spec.Replicas)
+	assert.Empty(t, deployment.Spec.Template.Annotations, "not restarted")
+}
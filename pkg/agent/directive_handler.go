@@ -2,9 +2,14 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
 	agentapi "github.com/datawire/ambassador-agent/pkg/api/agent"
 	"github.com/datawire/dlib/dlog"
 )
@@ -14,9 +19,18 @@ type DirectiveHandler interface {
 }
 
 type BasicDirectiveHandler struct {
-	DefaultMinReportPeriod time.Duration
-	rolloutsGetterFactory  rolloutsGetterFactory
-	secretsGetterFactory   secretsGetterFactory
+	DefaultMinReportPeriod   time.Duration
+	rolloutsGetterFactory    rolloutsGetterFactory
+	secretsGetterFactory     secretsGetterFactory
+	resourceClientFactory    resourceClientFactory
+	deploymentsGetterFactory deploymentsGetterFactory
+	configMapsGetterFactory  configMapsGetterFactory
+	podsGetterFactory        podsGetterFactory
+
+	// policy restricts the commands that run. All commands run when nil.
+	policy *commandPolicyFile
+	// audit records the directives and commands. Nothing is recorded when nil.
+	audit *auditSink
 }
 
 func (dh *BasicDirectiveHandler) HandleDirective(ctx context.Context, a *Agent, directive *agentapi.Directive) {
@@ -25,8 +39,10 @@ func (dh *BasicDirectiveHandler) HandleDirective(ctx context.Context, a *Agent,
 		return
 	}
 	ctx = dlog.WithField(ctx, "directive", directive.ID)
+	ctx = withDirectiveID(ctx, directive.ID)
 
 	dlog.Debug(ctx, "Directive received")
+	dh.audit.RecordDirective(ctx, directive)
 
 	if directive.StopReporting {
 		// The Director wants us to stop reporting
@@ -44,21 +60,79 @@ func (dh *BasicDirectiveHandler) HandleDirective(ctx context.Context, a *Agent,
 		a.SetMinReportPeriod(ctx, dur)
 	}
 
+	if directive.FullResync {
+		// The Director lost track of our state and wants a full snapshot
+		a.RequestFullResync(ctx)
+	}
+
+	if directive.MetricsReportPeriod != nil {
+		// The Director wants Envoy metrics at a different pace, or not at all
+		dur := directive.MetricsReportPeriod.AsDuration()
+		if dur > 0 {
+			dur = MaxDuration(dur, dh.DefaultMinReportPeriod) // respect configured minimum
+		}
+		a.SetMetricsReportPeriod(ctx, dur)
+	}
+
+	if len(directive.AcceptedContentTypes) > 0 {
+		// The Director tells us how it is able to decode our reports
+		a.SetAcceptedContentTypes(ctx, directive.AcceptedContentTypes)
+	}
+
 	for _, command := range directive.Commands {
 		if command.Message != "" {
 			dlog.Info(ctx, command.Message)
 		}
 
+		if result, ok := a.commandResults.Get(commandID(command)); ok {
+			// The Director delivered this command again, e.g. after a reconnection
+			dlog.Infof(ctx, "Command %s was already executed, reporting its result again", result.CommandId)
+			a.commandCompleted(ctx, result)
+			continue
+		}
+
 		if command.RolloutCommand != nil {
 			dh.handleRolloutCommand(ctx, command.RolloutCommand, a)
+		} else if command.DeploymentCommand != nil {
+			dh.handleDeploymentCommand(ctx, command.DeploymentCommand, a)
 		} else if command.SecretSyncCommand != nil {
 			dh.handleSecretSyncCommand(ctx, command.SecretSyncCommand, a)
+		} else if command.ConfigMapSyncCommand != nil {
+			dh.handleConfigMapSyncCommand(ctx, command.ConfigMapSyncCommand, a)
+		} else if command.ApplicationCommand != nil {
+			dh.handleApplicationCommand(ctx, command.ApplicationCommand, a)
+		} else if command.ResourcePatchCommand != nil {
+			dh.handleResourcePatchCommand(ctx, command.ResourcePatchCommand, a)
+		} else if command.LogsCommand != nil {
+			dh.handleLogsCommand(ctx, command.LogsCommand, a)
 		}
 	}
 
 	a.SetLastDirectiveID(ctx, directive.ID)
 }
 
+// commandID returns the ID of the given command, whatever its type.
+func commandID(command *agentapi.Command) string {
+	switch {
+	case command.RolloutCommand != nil:
+		return command.RolloutCommand.GetCommandId()
+	case command.DeploymentCommand != nil:
+		return command.DeploymentCommand.GetCommandId()
+	case command.ApplicationCommand != nil:
+		return command.ApplicationCommand.GetCommandId()
+	case command.SecretSyncCommand != nil:
+		return command.SecretSyncCommand.GetCommandId()
+	case command.ConfigMapSyncCommand != nil:
+		return command.ConfigMapSyncCommand.GetCommandId()
+	case command.ResourcePatchCommand != nil:
+		return command.ResourcePatchCommand.GetCommandId()
+	case command.LogsCommand != nil:
+		return command.LogsCommand.GetCommandId()
+	default:
+		return ""
+	}
+}
+
 func (dh *BasicDirectiveHandler) handleSecretSyncCommand(
 	ctx context.Context, cmdSchema *agentapi.SecretSyncCommand, a *Agent,
 ) {
@@ -95,14 +169,87 @@ func (dh *BasicDirectiveHandler) handleSecretSyncCommand(
 		namespace: namespace,
 		action:    secretSyncAction(agentapi.SecretSyncCommand_Action_name[action]),
 		secret:    secret,
+		dryRun:    cmdSchema.GetDryRun() || a.Env.commandsDryRun(),
+
+		secretType:  apiv1.SecretType(cmdSchema.GetType()),
+		labels:      cmdSchema.GetLabels(),
+		annotations: cmdSchema.GetAnnotations(),
+
+		allowUnowned: a.Env != nil && a.SecretSyncAllowUnowned,
+		adopt:        a.Env.adoptSecret(namespace, name),
 	}
 
-	err := cmd.RunWithClientFactory(ctx, dh.secretsGetterFactory)
-	if err != nil {
-		dlog.Errorf(ctx, "error running secret sync command %s: %s", cmd, err)
+	target := commandTarget{
+		kind:      commandKindSecretSync,
+		resource:  "secrets",
+		namespace: namespace,
+		name:      name,
+		action:    string(cmd.action),
+		object:    schema.GroupVersionKind{Version: "v1", Kind: "Secret"},
+	}
+	dh.runCommand(ctx, a, commandID, target, cmd, func(ctx context.Context) error {
+		return cmd.RunWithClientFactory(ctx, dh.secretsGetterFactory)
+	})
+}
+
+func (dh *BasicDirectiveHandler) handleConfigMapSyncCommand(
+	ctx context.Context, cmdSchema *agentapi.ConfigMapSyncCommand, a *Agent,
+) {
+	if dh.configMapsGetterFactory == nil {
+		dlog.Warn(ctx, "Received configmap sync command but does not know how to talk to kube API")
+		return
 	}
 
-	dh.reportCommandResult(ctx, commandID, cmd, err, a)
+	var (
+		name      = cmdSchema.GetName()
+		namespace = cmdSchema.GetNamespace()
+		action    = int32(cmdSchema.GetAction())
+		commandID = cmdSchema.GetCommandId()
+	)
+
+	if name == "" {
+		dlog.Warn(ctx, "ConfigMap sync command received without a configmap name")
+		return
+	}
+
+	if namespace == "" {
+		dlog.Warn(ctx, "ConfigMap sync command received without a configmap namespace")
+		return
+	}
+
+	if commandID == "" {
+		dlog.Warn(ctx, "ConfigMap sync command received without a command ID")
+		return
+	}
+
+	cmd := &configMapSyncCommand{
+		name:       name,
+		namespace:  namespace,
+		action:     configMapSyncAction(agentapi.ConfigMapSyncCommand_Action_name[action]),
+		data:       cmdSchema.GetData(),
+		binaryData: cmdSchema.GetBinaryData(),
+		dryRun:     cmdSchema.GetDryRun() || a.Env.commandsDryRun(),
+
+		labels:      cmdSchema.GetLabels(),
+		annotations: cmdSchema.GetAnnotations(),
+
+		allowUnowned: a.Env != nil && a.ConfigMapSyncAllowUnowned,
+	}
+
+	target := commandTarget{
+		kind:      commandKindConfigMapSync,
+		resource:  "configmaps",
+		namespace: namespace,
+		name:      name,
+		action:    string(cmd.action),
+		object:    schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+	}
+	dh.runCommand(ctx, a, commandID, target, cmd, func(ctx context.Context) error {
+		if a.Env.commandCache(namespace, name) {
+			return fmt.Errorf("configmap %s (%s) holds the results of the commands executed by the agent", name, namespace)
+		}
+		return cmd.RunWithClientFactory(ctx, dh.configMapsGetterFactory)
+	})
 }
 
 func (dh *BasicDirectiveHandler) handleRolloutCommand(
@@ -137,26 +284,358 @@ func (dh *BasicDirectiveHandler) handleRolloutCommand(
 		rolloutName: rolloutName,
 		namespace:   namespace,
 		action:      rolloutAction(agentapi.RolloutCommand_Action_name[action]),
+		container:   cmdSchema.GetContainer(),
+		image:       cmdSchema.GetImage(),
+		dryRun:      cmdSchema.GetDryRun() || a.Env.commandsDryRun(),
+	}
+	target := commandTarget{
+		kind:      commandKindRollout,
+		resource:  "rollouts.argoproj.io",
+		namespace: namespace,
+		name:      rolloutName,
+		action:    string(cmd.action),
+		object:    schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Rollout"},
+	}
+	dh.runCommand(ctx, a, commandID, target, cmd, func(ctx context.Context) error {
+		return cmd.RunWithClientFactory(ctx, dh.rolloutsGetterFactory)
+	})
+}
+
+func (dh *BasicDirectiveHandler) handleDeploymentCommand(
+	ctx context.Context, cmdSchema *agentapi.DeploymentCommand, a *Agent,
+) {
+	if dh.deploymentsGetterFactory == nil {
+		dlog.Warn(ctx, "Received deployment command but does not know how to talk to kube API")
+		return
+	}
+
+	deploymentName := cmdSchema.GetName()
+	namespace := cmdSchema.GetNamespace()
+	action := int32(cmdSchema.GetAction())
+	commandID := cmdSchema.GetCommandId()
+
+	if deploymentName == "" {
+		dlog.Warn(ctx, "Deployment command received without a deployment name")
+		return
+	}
+
+	if namespace == "" {
+		dlog.Warn(ctx, "Deployment command received without a namespace")
+		return
+	}
+
+	if commandID == "" {
+		dlog.Warn(ctx, "Deployment command received without a command ID")
+		return
+	}
+
+	cmd := &deploymentCommand{
+		deploymentName: deploymentName,
+		namespace:      namespace,
+		action:         deploymentAction(agentapi.DeploymentCommand_Action_name[action]),
+		replicas:       cmdSchema.GetReplicas(),
+		dryRun:         cmdSchema.GetDryRun() || a.Env.commandsDryRun(),
+	}
+	target := commandTarget{
+		kind:      commandKindDeployment,
+		resource:  "deployments.apps",
+		namespace: namespace,
+		name:      deploymentName,
+		action:    string(cmd.action),
+		object:    schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+	}
+	dh.runCommand(ctx, a, commandID, target, cmd, func(ctx context.Context) error {
+		if cmdSchema.GetAction() == agentapi.DeploymentCommand_UNSPECIFIED {
+			return fmt.Errorf("no action given for deployment %s (%s)", deploymentName, namespace)
+		}
+		return cmd.RunWithClientFactory(ctx, dh.deploymentsGetterFactory)
+	})
+}
+
+func (dh *BasicDirectiveHandler) handleApplicationCommand(
+	ctx context.Context, cmdSchema *agentapi.ApplicationCommand, a *Agent,
+) {
+	if dh.resourceClientFactory == nil {
+		dlog.Warn(ctx, "Received application command but does not know how to talk to kube API")
+		return
+	}
+
+	applicationName := cmdSchema.GetName()
+	namespace := cmdSchema.GetNamespace()
+	action := int32(cmdSchema.GetAction())
+	commandID := cmdSchema.GetCommandId()
+
+	if applicationName == "" {
+		dlog.Warn(ctx, "Application command received without an application name")
+		return
 	}
-	err := cmd.RunWithClientFactory(ctx, dh.rolloutsGetterFactory)
-	if err != nil {
-		dlog.Errorf(ctx, "error running rollout command %s: %s", cmd, err)
+
+	if namespace == "" {
+		dlog.Warn(ctx, "Application command received without a namespace")
+		return
+	}
+
+	if commandID == "" {
+		dlog.Warn(ctx, "Application command received without a command ID")
+		return
 	}
 
-	dh.reportCommandResult(ctx, commandID, cmd, err, a)
+	cmd := &applicationCommand{
+		applicationName: applicationName,
+		namespace:       namespace,
+		action:          applicationAction(agentapi.ApplicationCommand_Action_name[action]),
+		revision:        cmdSchema.GetRevision(),
+		prune:           cmdSchema.GetPrune(),
+		historyID:       cmdSchema.GetHistoryId(),
+		dryRun:          cmdSchema.GetDryRun() || a.Env.commandsDryRun(),
+	}
+	target := commandTarget{
+		kind:      commandKindApplication,
+		resource:  "applications.argoproj.io",
+		namespace: namespace,
+		name:      applicationName,
+		action:    string(cmd.action),
+		object:    schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Application"},
+	}
+	dh.runCommand(ctx, a, commandID, target, cmd, func(ctx context.Context) error {
+		if cmdSchema.GetAction() == agentapi.ApplicationCommand_UNSPECIFIED {
+			return fmt.Errorf("no action given for application %s (%s)", applicationName, namespace)
+		}
+		return cmd.RunWithClientFactory(ctx, dh.resourceClientFactory)
+	})
 }
 
-func (dh *BasicDirectiveHandler) reportCommandResult(
-	ctx context.Context, commandID string, cmd fmt.Stringer, cmdError error, a *Agent,
+func (dh *BasicDirectiveHandler) handleResourcePatchCommand(
+	ctx context.Context, cmdSchema *agentapi.ResourcePatchCommand, a *Agent,
 ) {
-	result := &agentapi.CommandResult{CommandId: commandID, Success: true}
-	if cmdError != nil {
+	if dh.resourceClientFactory == nil {
+		dlog.Warn(ctx, "Received resource patch command but does not know how to talk to kube API")
+		return
+	}
+
+	var (
+		gvr = schema.GroupVersionResource{
+			Group:    cmdSchema.GetGroup(),
+			Version:  cmdSchema.GetVersion(),
+			Resource: cmdSchema.GetResource(),
+		}
+		name      = cmdSchema.GetName()
+		namespace = cmdSchema.GetNamespace()
+		patchType = int32(cmdSchema.GetPatchType())
+		commandID = cmdSchema.GetCommandId()
+	)
+
+	if gvr.Version == "" || gvr.Resource == "" {
+		dlog.Warn(ctx, "Resource patch command received without a resource version or name")
+		return
+	}
+
+	if name == "" {
+		dlog.Warn(ctx, "Resource patch command received without an object name")
+		return
+	}
+
+	if commandID == "" {
+		dlog.Warn(ctx, "Resource patch command received without a command ID")
+		return
+	}
+
+	cmd := &resourcePatchCommand{
+		gvr:          gvr,
+		namespace:    namespace,
+		name:         name,
+		patchType:    resourcePatchType(agentapi.ResourcePatchCommand_PatchType_name[patchType]),
+		patch:        cmdSchema.GetPatch(),
+		fieldManager: cmdSchema.GetFieldManager(),
+		force:        cmdSchema.GetForce(),
+		dryRun:       cmdSchema.GetDryRun() || a.Env.commandsDryRun(),
+	}
+
+	target := commandTarget{
+		kind:      commandKindResourcePatch,
+		resource:  gvr.GroupResource().String(),
+		namespace: namespace,
+		name:      name,
+		action:    string(cmd.patchType),
+		object:    gvr.GroupVersion().WithKind(cmd.Kind()),
+	}
+	dh.runCommand(ctx, a, commandID, target, cmd, func(ctx context.Context) error {
+		if cmdSchema.GetPatchType() == agentapi.ResourcePatchCommand_UNSPECIFIED {
+			return fmt.Errorf("no patch type given for %s %s", gvr.GroupResource(), name)
+		}
+		if a.Env == nil || !resourcePatchAllowed(a.ResourcePatchAllowList, gvr, namespace) {
+			return fmt.Errorf("patching %s in namespace %q is not allowed by this agent", gvr.GroupResource(), namespace)
+		}
+		return cmd.RunWithClientFactory(ctx, dh.resourceClientFactory)
+	})
+}
+
+func (dh *BasicDirectiveHandler) handleLogsCommand(
+	ctx context.Context, cmdSchema *agentapi.LogsCommand, a *Agent,
+) {
+	if dh.podsGetterFactory == nil {
+		dlog.Warn(ctx, "Received logs command but does not know how to talk to kube API")
+		return
+	}
+
+	podName := cmdSchema.GetPod()
+	deploymentName := cmdSchema.GetDeployment()
+	namespace := cmdSchema.GetNamespace()
+	commandID := cmdSchema.GetCommandId()
+
+	if (podName == "") == (deploymentName == "") {
+		dlog.Warn(ctx, "Logs command received without either a pod or a deployment name")
+		return
+	}
+
+	if namespace == "" {
+		dlog.Warn(ctx, "Logs command received without a namespace")
+		return
+	}
+
+	if commandID == "" {
+		dlog.Warn(ctx, "Logs command received without a command ID")
+		return
+	}
+
+	maxBytes, redact := a.Env.logsOptions()
+	cmd := &logsCommand{
+		commandID:    commandID,
+		namespace:    namespace,
+		pod:          podName,
+		deployment:   deploymentName,
+		container:    cmdSchema.GetContainer(),
+		tailLines:    cmdSchema.GetTailLines(),
+		sinceSeconds: cmdSchema.GetSinceSeconds(),
+		maxBytes:     maxBytes,
+		redact:       redact,
+	}
+	target := commandTarget{
+		kind:      commandKindLogs,
+		resource:  "pods/log",
+		namespace: namespace,
+		name:      podName,
+		action:    "GET",
+		object:    schema.GroupVersionKind{Version: "v1", Kind: "Pod"},
+	}
+	if podName == "" {
+		target.name = deploymentName
+		target.object = schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	}
+	// The command may run in the background, after the watch loop has replaced comm
+	comm, apiKey := a.comm, a.AmbassadorAPIKey
+	dh.runCommand(ctx, a, commandID, target, cmd, func(ctx context.Context) error {
+		return comm.StreamLogs(ctx, apiKey, func(send func(*agentapi.LogsChunk) error) error {
+			return cmd.RunWithClientFactory(ctx, dh.podsGetterFactory, dh.deploymentsGetterFactory, send)
+		})
+	})
+}
+
+// runCommand runs a command, within the timeout configured for its kind, and
+// reports its result. When the agent has a command queue, the command runs in
+// the background after the commands that were submitted before on the same
+// target, and its result is reported by the watch loop. Commands that the
+// command policy denies are reported as failed without running.
+func (dh *BasicDirectiveHandler) runCommand(
+	ctx context.Context, a *Agent, commandID string, target commandTarget, cmd fmt.Stringer,
+	run func(context.Context) error,
+) {
+	kind := target.kind
+	if dh.policy != nil {
+		if allowed, reason := dh.policy.Evaluate(target); !allowed {
+			dlog.Warnf(ctx, "Not running %s command %s: %s", kind, cmd, reason)
+			commandsDenied.WithLabelValues(string(kind)).Inc()
+			result := newCommandResult(commandID, cmd, errors.New(reason))
+			dh.audit.RecordCommand(ctx, target, result, auditOutcomeDenied, 0)
+			a.commandCompleted(ctx, result)
+			return
+		}
+	}
+
+	execute := func() *agentapi.CommandResult {
+		start := time.Now()
+		runCtx := ctx
+		timeout := a.Env.commandTimeout(kind)
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			runCtx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		err := run(runCtx)
+		if err != nil && errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("timed out after %s: %w", timeout, err)
+			dlog.Errorf(ctx, "error running %s command %s: %s", kind, cmd, err)
+			result := &agentapi.CommandResult{CommandId: commandID, Message: err.Error(), Status: agentapi.CommandResult_TIMEOUT}
+			dh.audit.RecordCommand(ctx, target, result, auditOutcomeTimeout, time.Since(start))
+			return result
+		}
+		outcome := auditOutcomeSucceeded
+		if err != nil {
+			dlog.Errorf(ctx, "error running %s command %s: %s", kind, cmd, err)
+			outcome = auditOutcomeFailed
+		}
+		result := newCommandResult(commandID, cmd, err)
+		dh.audit.RecordCommand(ctx, target, result, outcome, time.Since(start))
+		return result
+	}
+
+	if a.commandQueue == nil {
+		a.commandCompleted(ctx, execute())
+		return
+	}
+	submitted := a.commandQueue.Submit(commandID, target.key(), func() {
+		result := execute()
+		select {
+		case a.completedCommands <- result:
+		case <-ctx.Done():
+		}
+	})
+	if !submitted {
+		dlog.Infof(ctx, "Command %s is already running, ignoring it", commandID)
+	}
+}
+
+// commandCompleted remembers the result of a command and reports it.
+func (a *Agent) commandCompleted(ctx context.Context, result *agentapi.CommandResult) {
+	a.commandResults.Add(ctx, result)
+	if err := a.ReportCommandResult(ctx, result); err != nil {
+		dlog.Errorf(ctx, "error reporting result of command %s: %s", result.CommandId, err)
+	}
+}
+
+// newCommandResult returns the result of a command that ran to completion.
+// The result of a dry run says what would have happened.
+func newCommandResult(commandID string, cmd fmt.Stringer, cmdError error) *agentapi.CommandResult {
+	result := &agentapi.CommandResult{CommandId: commandID, Success: true, Status: agentapi.CommandResult_SUCCEEDED}
+	if dr, ok := cmd.(interface{ DryRun() bool }); ok {
+		result.DryRun = dr.DryRun()
+	}
+	switch {
+	case cmdError != nil:
 		result.Success = false
+		result.Status = agentapi.CommandResult_FAILED
 		result.Message = cmdError.Error()
+	case result.DryRun:
+		if dm, ok := cmd.(interface{ DryRunMessage() string }); ok {
+			result.Message = dm.DryRunMessage()
+		} else {
+			result.Message = fmt.Sprintf("dry run, %s would succeed", cmd)
+		}
+	default:
+		if rv, ok := cmd.(interface{ ResourceVersion() string }); ok {
+			result.ResourceVersion = rv.ResourceVersion()
+		}
+		if rm, ok := cmd.(interface{ ResultMessage() string }); ok {
+			result.Message = rm.ResultMessage()
+		}
 	}
-	apiKey := a.AmbassadorAPIKey
-	err := a.comm.ReportCommandResult(ctx, result, apiKey)
-	if err != nil {
-		dlog.Errorf(ctx, "error reporting result of command %s: %s", cmd, err)
+	return result
+}
+
+// dryRunOption returns the value of the DryRun option of API requests.
+func dryRunOption(dryRun bool) []string {
+	if dryRun {
+		return []string{metav1.DryRunAll}
 	}
+	return nil
 }
@@ -102,8 +102,16 @@ There is a small set of Go code that uses the generated gRPC methods. The
 RPCComm Go structure encapsulates the gRPC client state, including its Go
 context, and tracks the Goroutine required to handle streaming responses from
 the Retrieve call. Once it has been created, the RPCComm communicates with the
-rest of the code via Go channels. RPCComm has a wrapper around the Report method
-that makes sure the Retrieve call is running.
+rest of the code via Go channels. The outcome of every RPC moves the RPCComm
+between the connecting, ready, degraded and auth-failed states. The Retrieve
+call is restarted with jittered exponential backoff, and is restarted right
+away when another RPC finds the Director reachable again. When the Director
+rejects the CLOUD_CONNECT_TOKEN, the Agent stops reporting and the token is
+only tried again after several minutes. gRPC keepalives detect dead
+connections. The TLS connection can trust an additional CA bundle, enforce a
+minimum TLS version, and present a client certificate that is reloaded whenever
+its files change. The connection goes through an HTTP CONNECT proxy when
+RPC_PROXY_URL is set, or as HTTPS_PROXY and NO_PROXY dictate otherwise.
 
 * Reporting layer
 
@@ -130,20 +138,85 @@ after performing the RPC to provide a simple, adjustable rate limit to
 reporting. The loop receives the RPC result as an event; that is its indication
 that the RPC is done.
 
-The loop also receives directives as events. The directive is executed right
-away in the same Goroutine, so commands must be fast/non-blocking for now. As
-the only command available is to log a simple string, this is not a problem.
+Once the Director has accepted a full snapshot, subsequent reports only carry
+the objects that were added, updated or deleted since, keyed by their UID. A
+full snapshot is sent again periodically, when the Director asks for a resync,
+and for good if the Director does not know how to receive deltas.
+
+When AGENT_SPOOL_DIR is set, a report that fails to send is kept on disk until
+it is replaced by a fresher one, and so are the results of commands, up to
+AGENT_SPOOL_MAX_COMMAND_RESULTS. Command results are sent in order once the
+connection to the Director is ready again, and the spooled snapshot is sent
+when there is nothing fresher to report, including after a restart.
+
+The loop also receives directives as events. The directive is handled right
+away in the same Goroutine, but the commands it carries run in the background on
+AGENT_COMMAND_WORKERS workers, one at a time per target object, and are
+reported as timed out when they take longer than AGENT_COMMAND_TIMEOUT (or the
+AGENT_COMMAND_TIMEOUTS override for their kind). Their results come back to the
+loop as events.
 Directives can also include a flag to tell the Agent to stop reporting and a
-duration to modify the reporting rate.
+duration to modify the reporting rate. They may also list the content types the
+Director accepts, in which case snapshots, snapshot deltas and diagnostics are
+compressed with gzip or zstd; otherwise they are sent as plain JSON. Finally, a directive
+may set the period at which Envoy metrics are scraped from AES_METRICS_URL and
+streamed to the Director; only the metrics in AGENT_METRICS_ALLOW_LIST are sent.
+Commands may also patch arbitrary resources with a server-side apply or a JSON
+merge patch, but only those listed in AGENT_RESOURCE_PATCH_ALLOW_LIST; the
+resourceVersion of the patched object is returned with the command result.
+Secrets can be given keys, of any type and with labels and annotations, have
+keys removed, or have their keys replaced at once, which fails rather than
+overwrite a secret that changed since the agent read it. The secrets that the
+agent creates are labeled app.kubernetes.io/managed-by=ambassador-agent, and
+other secrets are left alone unless AGENT_SECRET_SYNC_ALLOW_UNOWNED is true.
+Agents that predate the label left their secrets unlabeled, so after an upgrade
+those secrets are refused too until they are listed, as namespace/name, in
+AGENT_SECRET_SYNC_ADOPT; the first change made to a listed secret without a
+managed-by label labels it, after which it may be removed from the list.
+ConfigMaps are synced the same way, text and binary keys alike, and are left
+alone unless created by the agent or AGENT_CONFIGMAP_SYNC_ALLOW_UNOWNED is true.
+Deployments can be scaled, or restarted the way kubectl rollout restart does.
+Argo Rollouts can be paused, resumed, aborted, retried, promoted, restarted or
+given a new image with the semantics of the kubectl argo rollouts plugin.
+Argo CD Applications can be synced, hard-refreshed, rolled back, or have their
+running operation terminated, the way the Argo CD API server does it.
+The recent logs of a pod, or of the newest running pod of a deployment, are
+streamed to the Director with StreamLogs, up to AGENT_LOGS_MAX_BYTES; the lines
+that match an expression of AGENT_LOGS_REDACT are replaced before they are sent.
+The results of the last AGENT_COMMAND_CACHE_SIZE commands are remembered, in
+the AGENT_COMMAND_CACHE_CONFIGMAP ConfigMap if set, and a command delivered again
+gets its previous result instead of being executed again. Configmap sync commands
+never change that ConfigMap.
+The policy in AGENT_COMMAND_POLICY_FILE, if set, can allow or deny commands by
+kind, namespace, name and action; denied commands are reported as failed and
+counted in the metrics that the agent serves on /metrics. Commands with dry_run
+set, and all commands when AGENT_COMMANDS_DRY_RUN is true, are only validated by
+the API server and report what they would have done.
+Every directive and command is written to stdout as a JSON audit record, which
+says what was done to which object and how it ended but never the data written,
+and AGENT_AUDIT_EVENTS also records commands as Events on the objects they change.
 
 Finally, the loop receives new Watt snapshots as events. It uses the snapshot,
 which includes everything this Ambassador knows about the cluster, to generate a
-new report. If the new report is different from the last report that was sent,
-the Agent stores the new report as the next one to be sent. The snapshot also
-includes the information needed to determine whether the user has enabled the
-Agent (in the Ambassador Module). So the Agent must receive and process
-snapshots, even if all it discovers is that it is not enabled and doesn’t need
-to do anything else.
+new report. The Agent adds the pods, workloads (Deployments, StatefulSets,
+DaemonSets, Jobs and CronJobs), ConfigMaps and Endpoints that it watches itself,
+in fields of the Kubernetes section that the Emissary snapshot doesn't have when
+needed. Gateway API Gateways, HTTPRoutes and GRPCRoutes are added too when the
+cluster serves them, and ResolveIngress falls back to the HTTPRoutes of a
+service when no Mapping gives its hostname. The resources listed in
+AGENT_EXTRA_RESOURCES are added to the ExtraResources field while the cluster
+serves them, so that new kinds of resources can be reported without changing the
+Agent. The resources of kube-system, and those that match AGENT_EXCLUDE_SELECTOR
+or are in a namespace that matches AGENT_EXCLUDE_NAMESPACE_SELECTOR, are left
+out of all of them, and no snapshot is made while the excluded namespaces can't
+be listed. The snapshot is then sanitized, and the fields named by the
+AGENT_SNAPSHOT_REDACT rules are dropped or hashed in a copy of it that is
+reported, as ingresses are resolved from the snapshot as is. If the new report
+is different from the last report that was sent, the Agent stores the new report
+as the next one to be sent. The snapshot also includes the information needed to
+determine whether the user has enabled the Agent (in the Ambassador Module). So
+the Agent must receive and process snapshots, even if all it discovers is that
+it is not enabled and doesn’t need to do anything else.
 
 Connectivity to the Director is handled by the communication layer described
 above. The RPCComm instance is first created when the Agent decides to report.
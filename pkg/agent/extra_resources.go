@@ -0,0 +1,100 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/datawire/ambassador-agent/pkg/agent/watchers"
+	"github.com/datawire/dlib/dlog"
+)
+
+// extraResourcesStore holds the resources that the agent is configured to watch
+// on top of the ones that it knows of.
+type extraResourcesStore struct {
+	resources []schema.GroupVersionResource
+	stores    []unstructuredStore
+}
+
+func newExtraResourcesStore(resources []schema.GroupVersionResource) *extraResourcesStore {
+	return &extraResourcesStore{
+		resources: resources,
+		stores:    make([]unstructuredStore, len(resources)),
+	}
+}
+
+// LoadSnapshot adds the extra resources that are watched, and not excluded, to the snapshot.
+func (s *extraResourcesStore) LoadSnapshot(ctx context.Context, snapshot *watchers.Snapshot, exclusions *watchers.Exclusions) {
+	var extra map[string][]*unstructured.Unstructured
+	for i, gvr := range s.resources {
+		store := &s.stores[i]
+		if !store.Synced() {
+			continue
+		}
+		if extra == nil {
+			extra = make(map[string][]*unstructured.Unstructured)
+		}
+		// an empty list tells that the resource is served, but that there are none
+		sotw := watchers.Filter(ctx, exclusions, store.StateOfWorld())
+		extra[resourceArg(gvr)] = sotw
+		dlog.Debugf(ctx, "Found %d %s", len(sotw), resourceArg(gvr))
+	}
+	snapshot.Kubernetes.ExtraResources = extra
+}
+
+// extraResourcesWatch watches the extra resources that the cluster serves. Like
+// gatewayAPIWatch, it discovers them again periodically, so that they appear in
+// the snapshot once their CRDs are installed, and disappear once they are removed.
+func (a *Agent) extraResourcesWatch(ctx context.Context) {
+	if a.extraResources == nil || len(a.extraResources.resources) == 0 {
+		return
+	}
+	watches, err := newUnstructuredWatches()
+	if err != nil {
+		dlog.Errorf(ctx, "Error making kates client: %s", err)
+		return
+	}
+	defer watches.cancel()
+
+	for {
+		resourceLists := serverResources(ctx)
+		for i, gvr := range a.extraResources.resources {
+			served := servedResource(resourceLists, gvr.Group, gvr.Resource, gvr.Version)
+			watches.update(ctx, resourceArg(gvr), served, &a.extraResources.stores[i])
+		}
+
+		// recheck conditions periodically
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Minute):
+		}
+	}
+}
+
+// resourceArg returns the resource.version.group form of the resource.
+func resourceArg(gvr schema.GroupVersionResource) string {
+	return gvr.Resource + "." + gvr.Version + "." + gvr.Group
+}
+
+// parseExtraResources parses the resource.version.group entries of
+// AGENT_EXTRA_RESOURCES, e.g. "certificates.v1.cert-manager.io".
+func parseExtraResources(entries []string) ([]schema.GroupVersionResource, error) {
+	resources := make([]schema.GroupVersionResource, 0, len(entries))
+	seen := make(map[schema.GroupVersionResource]struct{}, len(entries))
+	for _, entry := range entries {
+		gvr, _ := schema.ParseResourceArg(entry)
+		if gvr == nil || gvr.Resource == "" || gvr.Version == "" || gvr.Group == "" {
+			return nil, fmt.Errorf("invalid extra resource %q, must be resource.version.group", entry)
+		}
+		if _, ok := seen[*gvr]; ok {
+			continue
+		}
+		seen[*gvr] = struct{}{}
+		resources = append(resources, *gvr)
+	}
+	return resources, nil
+}
@@ -0,0 +1,86 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/datawire/ambassador-agent/pkg/agent/watchers"
+	snapshotTypes "github.com/emissary-ingress/emissary/v3/pkg/snapshot/v1"
+)
+
+func TestParseExtraResources(t *testing.T) {
+	resources, err := parseExtraResources([]string{
+		"certificates.v1.cert-manager.io",
+		"virtualservices.v1beta1.networking.istio.io",
+		"certificates.v1.cert-manager.io",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []schema.GroupVersionResource{
+		{Group: "cert-manager.io", Version: "v1", Resource: "certificates"},
+		{Group: "networking.istio.io", Version: "v1beta1", Resource: "virtualservices"},
+	}, resources)
+
+	_, err = parseExtraResources([]string{"certificates.cert-manager"})
+	assert.EqualError(t, err, `invalid extra resource "certificates.cert-manager", must be resource.version.group`)
+	_, err = parseExtraResources([]string{"certificates"})
+	assert.Error(t, err)
+
+	env, err := LoadEnv(func(key string) (string, bool) {
+		if key == "AGENT_EXTRA_RESOURCES" {
+			return "certificates.v1.cert-manager.io  issuers.v1.cert-manager.io", true
+		}
+		return "", false
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []schema.GroupVersionResource{
+		{Group: "cert-manager.io", Version: "v1", Resource: "certificates"},
+		{Group: "cert-manager.io", Version: "v1", Resource: "issuers"},
+	}, env.ExtraResources)
+}
+
+func TestExtraResourcesStore_LoadSnapshot(t *testing.T) {
+	ctx := context.Background()
+	s := newExtraResourcesStore([]schema.GroupVersionResource{
+		{Group: "cert-manager.io", Version: "v1", Resource: "certificates"},
+		{Group: "cert-manager.io", Version: "v1", Resource: "issuers"},
+	})
+	snapshot := watchers.NewSnapshot(&snapshotTypes.Snapshot{Kubernetes: &snapshotTypes.KubernetesSnapshot{}})
+
+	// nothing is reported until a resource is watched
+	s.LoadSnapshot(ctx, snapshot, nil)
+	assert.Nil(t, snapshot.Kubernetes.ExtraResources)
+
+	cert := &unstructured.Unstructured{}
+	cert.SetName("example-com")
+	cert.SetNamespace("default")
+	require.NoError(t, s.stores[0].FromCallback(&GenericCallback{Sotw: []interface{}{cert}}))
+	require.NoError(t, s.stores[1].FromCallback(&GenericCallback{}))
+	s.LoadSnapshot(ctx, snapshot, nil)
+	assert.Equal(t, map[string][]*unstructured.Unstructured{
+		"certificates.v1.cert-manager.io": {cert},
+		"issuers.v1.cert-manager.io":      {},
+	}, snapshot.Kubernetes.ExtraResources)
+
+	data, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+	var decoded struct {
+		Kubernetes struct {
+			ExtraResources map[string][]map[string]any
+		}
+	}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Len(t, decoded.Kubernetes.ExtraResources["certificates.v1.cert-manager.io"], 1)
+
+	// and a resource that is no longer served disappears
+	s.stores[0].Reset()
+	s.LoadSnapshot(ctx, snapshot, nil)
+	assert.Equal(t, map[string][]*unstructured.Unstructured{
+		"issuers.v1.cert-manager.io": {},
+	}, snapshot.Kubernetes.ExtraResources)
+}
@@ -0,0 +1,246 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/datawire/ambassador-agent/pkg/agent/watchers"
+	rpc "github.com/datawire/ambassador-agent/rpc/agent"
+	"github.com/datawire/dlib/dlog"
+)
+
+const (
+	gatewayAPIGroup = "gateway.networking.k8s.io"
+	// gatewayNameLabel is set on the Services that implementations create for Gateways.
+	gatewayNameLabel = "gateway.networking.k8s.io/gateway-name"
+)
+
+// gatewayAPIStore holds the Gateway API resources of the cluster.
+type gatewayAPIStore struct {
+	gateways   unstructuredStore
+	httpRoutes unstructuredStore
+	grpcRoutes unstructuredStore
+}
+
+// gatewayAPIResource is a Gateway API resource that the agent watches, with the
+// versions that it understands, the preferred one first.
+type gatewayAPIResource struct {
+	resource string
+	versions []string
+	store    *unstructuredStore
+}
+
+func (s *gatewayAPIStore) resources() []gatewayAPIResource {
+	return []gatewayAPIResource{
+		{resource: "gateways", versions: []string{"v1", "v1beta1"}, store: &s.gateways},
+		{resource: "httproutes", versions: []string{"v1", "v1beta1"}, store: &s.httpRoutes},
+		{resource: "grpcroutes", versions: []string{"v1", "v1alpha2"}, store: &s.grpcRoutes},
+	}
+}
+
+// LoadSnapshot adds the Gateway API resources that are not excluded to the snapshot.
+func (s *gatewayAPIStore) LoadSnapshot(ctx context.Context, snapshot *watchers.Snapshot, exclusions *watchers.Exclusions) {
+	k8sSnap := snapshot.Kubernetes
+	k8sSnap.GatewayAPIGateways = watchers.Filter(ctx, exclusions, s.gateways.StateOfWorld())
+	dlog.Debugf(ctx, "Found %d Gateway API gateways", len(k8sSnap.GatewayAPIGateways))
+
+	k8sSnap.GatewayAPIHTTPRoutes = watchers.Filter(ctx, exclusions, s.httpRoutes.StateOfWorld())
+	dlog.Debugf(ctx, "Found %d Gateway API HTTPRoutes", len(k8sSnap.GatewayAPIHTTPRoutes))
+
+	k8sSnap.GatewayAPIGRPCRoutes = watchers.Filter(ctx, exclusions, s.grpcRoutes.StateOfWorld())
+	dlog.Debugf(ctx, "Found %d Gateway API GRPCRoutes", len(k8sSnap.GatewayAPIGRPCRoutes))
+}
+
+// gatewayAPIWatch watches the Gateway API resources, in the most recent version
+// that both the cluster and the agent know, like argoWatch does for Argo. The
+// resources are discovered again periodically, as their CRDs may be installed,
+// upgraded or removed at any time.
+func (a *Agent) gatewayAPIWatch(ctx context.Context) {
+	watches, err := newUnstructuredWatches()
+	if err != nil {
+		dlog.Errorf(ctx, "Error making kates client: %s", err)
+		return
+	}
+	defer watches.cancel()
+
+	for {
+		resourceLists := serverResources(ctx)
+		for _, r := range a.gatewayAPI.resources() {
+			gvr := servedResource(resourceLists, gatewayAPIGroup, r.resource, r.versions...)
+			watches.update(ctx, r.resource+"."+gatewayAPIGroup, gvr, r.store)
+		}
+
+		// recheck conditions periodically
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Minute):
+		}
+	}
+}
+
+// gatewayAPIRef is a parentRef or backendRef of a Gateway API route.
+type gatewayAPIRef struct {
+	Group       string `json:"group,omitempty"`
+	Kind        string `json:"kind,omitempty"`
+	Namespace   string `json:"namespace,omitempty"`
+	Name        string `json:"name"`
+	SectionName string `json:"sectionName,omitempty"`
+	Port        int32  `json:"port,omitempty"`
+}
+
+// gatewayAPIRoute holds the fields of an HTTPRoute, of any version, that tell how
+// a service is reached.
+type gatewayAPIRoute struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              struct {
+		ParentRefs []gatewayAPIRef `json:"parentRefs,omitempty"`
+		Hostnames  []string        `json:"hostnames,omitempty"`
+		Rules      []struct {
+			BackendRefs []gatewayAPIRef `json:"backendRefs,omitempty"`
+		} `json:"rules,omitempty"`
+	} `json:"spec"`
+}
+
+type gatewayAPIListener struct {
+	Name     string `json:"name"`
+	Hostname string `json:"hostname,omitempty"`
+	Port     int32  `json:"port"`
+	Protocol string `json:"protocol"`
+}
+
+// gatewayAPIGateway holds the fields of a Gateway, of any version, that tell how
+// its routes are reached.
+type gatewayAPIGateway struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              struct {
+		Listeners []gatewayAPIListener `json:"listeners,omitempty"`
+	} `json:"spec"`
+	Status struct {
+		Addresses []struct {
+			Value string `json:"value"`
+		} `json:"addresses,omitempty"`
+	} `json:"status,omitempty"`
+}
+
+// routesTo tells whether a rule of the route sends requests to the service.
+func (r *gatewayAPIRoute) routesTo(svc *core.Service) bool {
+	for _, rule := range r.Spec.Rules {
+		for _, ref := range rule.BackendRefs {
+			if ref.Group != "" || (ref.Kind != "" && ref.Kind != "Service") {
+				continue
+			}
+			namespace := ref.Namespace
+			if namespace == "" {
+				namespace = r.Namespace
+			}
+			if ref.Name == svc.Name && namespace == svc.Namespace {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// findGatewayIngress resolves the ingress of the service from the HTTPRoutes that
+// send requests to it. The L5 host is the first hostname of the route, or else of
+// the listener of its Gateway, and the L3 host is the Service of the Gateway, or
+// else the address of the Gateway.
+func findGatewayIngress(ksn *watchers.KubernetesSnapshot, svc *core.Service, clusterDomain string) *rpc.IngressInfoResponse {
+	for _, u := range ksn.GatewayAPIHTTPRoutes {
+		var route gatewayAPIRoute
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &route); err != nil {
+			continue
+		}
+		if !route.routesTo(svc) {
+			continue
+		}
+		for _, parent := range route.Spec.ParentRefs {
+			gw, listener := findGatewayListener(ksn, route.Namespace, parent)
+			if gw == nil {
+				continue
+			}
+			hostName := findRouteHostname(route.Spec.Hostnames, listener.Hostname)
+			l3Host := gatewayAddress(ksn, gw, clusterDomain)
+			if hostName == "" || l3Host == "" {
+				continue
+			}
+			return &rpc.IngressInfoResponse{
+				L3Host:  l3Host,
+				L4Proto: string(core.ProtocolTCP),
+				Port:    listener.Port,
+				L5Host:  hostName,
+				UseTls:  listener.Protocol == "HTTPS",
+			}
+		}
+	}
+	return nil
+}
+
+// findGatewayListener returns the Gateway that the parentRef of a route in the given
+// namespace refers to, and the listener of the Gateway that the route attaches to.
+func findGatewayListener(
+	ksn *watchers.KubernetesSnapshot, namespace string, parent gatewayAPIRef,
+) (*gatewayAPIGateway, *gatewayAPIListener) {
+	if (parent.Group != "" && parent.Group != gatewayAPIGroup) || (parent.Kind != "" && parent.Kind != "Gateway") {
+		return nil, nil
+	}
+	if parent.Namespace != "" {
+		namespace = parent.Namespace
+	}
+	for _, u := range ksn.GatewayAPIGateways {
+		if u.GetName() != parent.Name || u.GetNamespace() != namespace {
+			continue
+		}
+		var gw gatewayAPIGateway
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &gw); err != nil {
+			return nil, nil
+		}
+		var found *gatewayAPIListener
+		for i := range gw.Spec.Listeners {
+			l := &gw.Spec.Listeners[i]
+			switch {
+			case parent.SectionName != "" && l.Name != parent.SectionName:
+			case parent.Port != 0 && l.Port != parent.Port:
+			case found == nil, l.Protocol == "HTTPS" && found.Protocol != "HTTPS":
+				found = l
+			}
+		}
+		if found == nil {
+			return nil, nil
+		}
+		return &gw, found
+	}
+	return nil, nil
+}
+
+// findRouteHostname returns the first hostname of the route that isn't a wildcard,
+// or else the hostname of the listener.
+func findRouteHostname(hostnames []string, listenerHostname string) string {
+	for _, hostname := range append(hostnames, listenerHostname) {
+		if hostname != "" && !strings.HasPrefix(hostname, "*") {
+			return hostname
+		}
+	}
+	return ""
+}
+
+// gatewayAddress returns the in-cluster name of the Service that implements the
+// Gateway, or else the first address that the Gateway reports.
+func gatewayAddress(ksn *watchers.KubernetesSnapshot, gw *gatewayAPIGateway, clusterDomain string) string {
+	for _, svc := range ksn.Services {
+		if svc.Namespace == gw.Namespace && svc.Labels[gatewayNameLabel] == gw.Name {
+			return fmt.Sprintf("%s.%s.svc.%s", svc.Name, svc.Namespace, clusterDomain)
+		}
+	}
+	if len(gw.Status.Addresses) > 0 {
+		return gw.Status.Addresses[0].Value
+	}
+	return ""
+}
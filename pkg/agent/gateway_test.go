@@ -0,0 +1,111 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/datawire/ambassador-agent/pkg/agent/watchers"
+	rpc "github.com/datawire/ambassador-agent/rpc/agent"
+	snapshotTypes "github.com/emissary-ingress/emissary/v3/pkg/snapshot/v1"
+)
+
+func newGatewayAPISnapshot(routeHostnames []any) *watchers.Snapshot {
+	newObj := func(obj map[string]any) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: obj}
+	}
+	snapshot := watchers.NewSnapshot(&snapshotTypes.Snapshot{Kubernetes: &snapshotTypes.KubernetesSnapshot{
+		Services: []*core.Service{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "quote", Namespace: "apps", UID: "quote-uid"},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "public-gateway-istio",
+					Namespace: "gateways",
+					Labels:    map[string]string{gatewayNameLabel: "public"},
+				},
+			},
+		},
+	}})
+	snapshot.Kubernetes.GatewayAPIGateways = []*unstructured.Unstructured{newObj(map[string]any{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "Gateway",
+		"metadata":   map[string]any{"name": "public", "namespace": "gateways"},
+		"spec": map[string]any{
+			"listeners": []any{
+				map[string]any{"name": "http", "port": int64(80), "protocol": "HTTP", "hostname": "*.example.com"},
+				map[string]any{"name": "https", "port": int64(443), "protocol": "HTTPS", "hostname": "apps.example.com"},
+			},
+		},
+	})}
+	snapshot.Kubernetes.GatewayAPIHTTPRoutes = []*unstructured.Unstructured{newObj(map[string]any{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "HTTPRoute",
+		"metadata":   map[string]any{"name": "quote", "namespace": "apps"},
+		"spec": map[string]any{
+			"parentRefs": []any{map[string]any{"name": "public", "namespace": "gateways"}},
+			"hostnames":  routeHostnames,
+			"rules": []any{map[string]any{
+				"backendRefs": []any{map[string]any{"name": "quote", "port": int64(3000)}},
+			}},
+		},
+	})}
+	return snapshot
+}
+
+func TestGetSnapshotIngress_GatewayAPI(t *testing.T) {
+	request := &rpc.IngressInfoRequest{ServiceId: "quote-uid"}
+
+	t.Run("route hostname", func(t *testing.T) {
+		a := &Agent{clusterDomain: "cluster.local", currentSnapshot: newGatewayAPISnapshot([]any{"*.example.com", "quote.example.com"})}
+		info, msg := a.getSnapshotIngress(request)
+		require.NotNil(t, info, msg)
+		assert.Equal(t, "public-gateway-istio.gateways.svc.cluster.local", info.L3Host)
+		assert.Equal(t, "quote.example.com", info.L5Host)
+		assert.Equal(t, int32(443), info.Port)
+		assert.Equal(t, "TCP", info.L4Proto)
+		assert.True(t, info.UseTls)
+	})
+
+	t.Run("listener hostname", func(t *testing.T) {
+		a := &Agent{clusterDomain: "cluster.local", currentSnapshot: newGatewayAPISnapshot(nil)}
+		info, msg := a.getSnapshotIngress(request)
+		require.NotNil(t, info, msg)
+		assert.Equal(t, "apps.example.com", info.L5Host)
+	})
+
+	t.Run("gateway address", func(t *testing.T) {
+		snapshot := newGatewayAPISnapshot([]any{"quote.example.com"})
+		snapshot.Kubernetes.Services = snapshot.Kubernetes.Services[:1]
+		gw := snapshot.Kubernetes.GatewayAPIGateways[0]
+		require.NoError(t, unstructured.SetNestedSlice(gw.Object, []any{
+			map[string]any{"type": "IPAddress", "value": "203.0.113.7"},
+		}, "status", "addresses"))
+		a := &Agent{clusterDomain: "cluster.local", currentSnapshot: snapshot}
+		info, msg := a.getSnapshotIngress(request)
+		require.NotNil(t, info, msg)
+		assert.Equal(t, "203.0.113.7", info.L3Host)
+	})
+
+	t.Run("not routed", func(t *testing.T) {
+		snapshot := newGatewayAPISnapshot([]any{"quote.example.com"})
+		snapshot.Kubernetes.GatewayAPIHTTPRoutes[0].SetNamespace("other")
+		a := &Agent{clusterDomain: "cluster.local", currentSnapshot: snapshot}
+		info, msg := a.getSnapshotIngress(request)
+		assert.Nil(t, info)
+		assert.Equal(t, `Could not resolve hostname in mappings or HTTPRoutes of service "quote-uid"`, msg)
+
+		// and the default is used instead
+		resp, err := a.ResolveIngress(context.Background(), &rpc.IngressInfoRequest{
+			ServiceId: "quote-uid", ServiceName: "quote", Namespace: "apps", ServicePortNumber: 3000,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "quote.apps.svc.cluster.local", resp.L3Host)
+	})
+}
@@ -0,0 +1,275 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	appsv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	agentapi "github.com/datawire/ambassador-agent/pkg/api/agent"
+)
+
+const (
+	// defaultLogsMaxBytes is the byte limit of the logs of a command when none is configured.
+	defaultLogsMaxBytes = 1024 * 1024
+	// redactedLine replaces the lines of logs that match a redaction rule.
+	redactedLine = "[redacted by ambassador-agent]\n"
+)
+
+// podsGetterFactory is a factory for creating PodsGetter.
+type podsGetterFactory func() (corev1.PodsGetter, error)
+
+// logsCommand gets the recent logs of a pod, or of a running pod of a deployment,
+// and sends them in chunks.
+type logsCommand struct {
+	commandID    string
+	namespace    string
+	pod          string
+	deployment   string
+	container    string
+	tailLines    int64
+	sinceSeconds int64
+
+	// maxBytes is how much of the logs are sent at most, defaultLogsMaxBytes if zero.
+	maxBytes int
+	// redact holds the expressions of the lines that are redacted before they are sent.
+	redact []*regexp.Regexp
+	// chunkSize is the size of the chunks the logs are sent in, chunkSize if zero.
+	chunkSize int
+
+	// sentPod, sentBytes and truncated say what was sent, once the command has run.
+	sentPod   string
+	sentBytes int
+	truncated bool
+}
+
+func (l *logsCommand) String() string {
+	if l.pod == "" {
+		return fmt.Sprintf("<deployment=%s namespace=%s container=%s>", l.deployment, l.namespace, l.container)
+	}
+	return fmt.Sprintf("<pod=%s namespace=%s container=%s>", l.pod, l.namespace, l.container)
+}
+
+// ResultMessage tells which logs were sent.
+func (l *logsCommand) ResultMessage() string {
+	msg := fmt.Sprintf("sent %d bytes of logs of pod %s (%s)", l.sentBytes, l.sentPod, l.namespace)
+	if l.truncated {
+		msg += fmt.Sprintf(", truncated at %d bytes", l.limit())
+	}
+	return msg
+}
+
+func (l *logsCommand) limit() int {
+	if l.maxBytes <= 0 {
+		return defaultLogsMaxBytes
+	}
+	return l.maxBytes
+}
+
+// RunWithClientFactory gets the logs using podsClientFactory, and deploymentsClientFactory
+// to find the pods of a deployment, and sends them with send.
+func (l *logsCommand) RunWithClientFactory(
+	ctx context.Context,
+	podsClientFactory podsGetterFactory,
+	deploymentsClientFactory deploymentsGetterFactory,
+	send func(*agentapi.LogsChunk) error,
+) error {
+	client, err := podsClientFactory()
+	if err != nil {
+		return err
+	}
+
+	pod := l.pod
+	if pod == "" {
+		if deploymentsClientFactory == nil {
+			return fmt.Errorf("unable to find the pods of deployment %s: no deployments client", l.deployment)
+		}
+		deployments, err := deploymentsClientFactory()
+		if err != nil {
+			return err
+		}
+		if pod, err = l.deploymentPod(ctx, client, deployments); err != nil {
+			return err
+		}
+	}
+
+	opts := &apiv1.PodLogOptions{Container: l.container}
+	if l.tailLines > 0 {
+		opts.TailLines = &l.tailLines
+	}
+	if l.sinceSeconds > 0 {
+		opts.SinceSeconds = &l.sinceSeconds
+	}
+	stream, err := client.Pods(l.namespace).GetLogs(pod, opts).Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the logs of pod %s (%s): %w", pod, l.namespace, err)
+	}
+	defer stream.Close()
+
+	l.sentPod = pod
+	return l.sendLogs(stream, send)
+}
+
+// deploymentPod returns the name of the most recently created running pod of the deployment.
+func (l *logsCommand) deploymentPod(
+	ctx context.Context, pods corev1.PodsGetter, deployments appsv1.DeploymentsGetter,
+) (string, error) {
+	deployment, err := deployments.Deployments(l.namespace).Get(ctx, l.deployment, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get the deployment %s: %w", l.deployment, err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return "", fmt.Errorf("invalid selector of deployment %s: %w", l.deployment, err)
+	}
+	list, err := pods.Pods(l.namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return "", fmt.Errorf("failed to list the pods of deployment %s: %w", l.deployment, err)
+	}
+
+	running := make([]apiv1.Pod, 0, len(list.Items))
+	for _, pod := range list.Items {
+		if pod.Status.Phase == apiv1.PodRunning && pod.DeletionTimestamp == nil {
+			running = append(running, pod)
+		}
+	}
+	if len(running) == 0 {
+		return "", fmt.Errorf("deployment %s (%s) has no running pods", l.deployment, l.namespace)
+	}
+	sort.Slice(running, func(i, j int) bool {
+		return running[j].CreationTimestamp.Before(&running[i].CreationTimestamp)
+	})
+	return running[0].Name, nil
+}
+
+// sendLogs sends the lines read from logs, redacted, in chunks of at most chunkSize
+// bytes, and stops at the byte limit. Lines are redacted as a whole before the ones
+// longer than a chunk are split.
+func (l *logsCommand) sendLogs(logs io.Reader, send func(*agentapi.LogsChunk) error) error {
+	size := l.chunkSize
+	if size <= 0 {
+		size = chunkSize
+	}
+	limit := l.limit()
+	reader := bufio.NewReaderSize(logs, size)
+	buf := make([]byte, 0, size)
+
+	flush := func() error {
+		if len(buf) == 0 && !l.truncated {
+			return nil
+		}
+		err := send(&agentapi.LogsChunk{
+			CommandId: l.commandID,
+			Pod:       l.sentPod,
+			Container: l.container,
+			Chunk:     buf,
+			Truncated: l.truncated,
+		})
+		l.sentBytes += len(buf)
+		buf = make([]byte, 0, size)
+		return err
+	}
+
+	for {
+		line, err := readLine(reader, limit-l.sentBytes-len(buf))
+		if err == nil && line[len(line)-1] != '\n' {
+			// the line doesn't fit, and its end would be sent without the start
+			// that tells whether it is redacted
+			l.truncated = true
+			return flush()
+		}
+		if len(line) > 0 {
+			line = l.redactLine(line)
+			if l.sentBytes+len(buf)+len(line) > limit {
+				l.truncated = true
+				return flush()
+			}
+			if len(buf)+len(line) > size {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+			for len(line) > size {
+				buf = append(buf, line[:size]...)
+				if err := flush(); err != nil {
+					return err
+				}
+				line = line[size:]
+			}
+			buf = append(buf, line...)
+		}
+		switch {
+		case err == nil:
+		case errors.Is(err, io.EOF):
+			return flush()
+		default:
+			return fmt.Errorf("failed to read the logs of pod %s (%s): %w", l.sentPod, l.namespace, err)
+		}
+	}
+}
+
+// readLine reads a whole line, so that it can be redacted as a whole, but stops
+// reading once it is longer than maxLen, as it won't be sent then. A line that
+// stops early has no trailing newline and comes with a nil error.
+func readLine(reader *bufio.Reader, maxLen int) ([]byte, error) {
+	var line []byte
+	for {
+		fragment, err := reader.ReadSlice('\n')
+		line = append(line, fragment...)
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return line, err
+		}
+		if len(line) > maxLen {
+			return line, nil
+		}
+	}
+}
+
+// redactLine returns the line, or a placeholder when it matches a redaction rule.
+func (l *logsCommand) redactLine(line []byte) []byte {
+	for _, re := range l.redact {
+		if re.Match(line) {
+			return []byte(redactedLine)
+		}
+	}
+	return line
+}
+
+// logsOptions returns the byte limit and the redaction rules of the logs commands.
+func (e *Env) logsOptions() (int, []*regexp.Regexp) {
+	if e == nil {
+		return defaultLogsMaxBytes, nil
+	}
+	return e.LogsMaxBytes, e.LogsRedact
+}
+
+// parseLogsRedact parses the whitespace separated expressions of AGENT_LOGS_REDACT.
+func parseLogsRedact(exprs []string) ([]*regexp.Regexp, error) {
+	var redact []*regexp.Regexp
+	for _, expr := range exprs {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid logs redaction expression %q: %w", expr, err)
+		}
+		redact = append(redact, re)
+	}
+	return redact, nil
+}
+
+// NewPodsGetter instantiates a client to interact with the Kubernetes pod API.
+func NewPodsGetter() (corev1.PodsGetter, error) {
+	kubeConfig, err := newK8sRestClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return corev1.NewForConfig(kubeConfig)
+}
@@ -0,0 +1,206 @@
+package agent
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiappsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	appsv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/datawire/ambassador-agent/pkg/api/agent"
+	"github.com/datawire/dlib/dlog"
+)
+
+func TestLogsCommand_sendLogs(t *testing.T) {
+	logs := "starting\n" +
+		"password=hunter2\n" +
+		"listening on :8080\n" +
+		"GET / 200\n"
+
+	type testcase struct {
+		logs      string
+		maxBytes  int
+		chunkSize int
+		redact    []*regexp.Regexp
+
+		expectChunks    []string
+		expectTruncated bool
+	}
+	cases := map[string]testcase{
+		"one chunk": {
+			expectChunks: []string{logs},
+		},
+		"redacted": {
+			redact: []*regexp.Regexp{regexp.MustCompile(`(?i)password`)},
+			expectChunks: []string{
+				"starting\n" + redactedLine + "listening on :8080\nGET / 200\n",
+			},
+		},
+		"chunked by line": {
+			chunkSize:    32,
+			expectChunks: []string{"starting\npassword=hunter2\n", "listening on :8080\nGET / 200\n"},
+		},
+		"redacted across chunks": {
+			chunkSize: 32,
+			redact:    []*regexp.Regexp{regexp.MustCompile(`(?i)password`)},
+			// the first 32 bytes of the line end in the middle of "password"
+			logs:         "starting\n" + strings.Repeat("a", 28) + "password=hunter2\n",
+			expectChunks: []string{"starting\n", redactedLine},
+		},
+		"redacted line over the limit": {
+			maxBytes:  60,
+			chunkSize: 16,
+			redact:    []*regexp.Regexp{regexp.MustCompile(`password`)},
+			// only the start of the line is read before the limit, the rest of it must
+			// not be sent on its own
+			logs:            "starting\n" + "password" + strings.Repeat("x", 56) + "=hunter2\n",
+			expectChunks:    []string{"starting\n"},
+			expectTruncated: true,
+		},
+		"long line split": {
+			chunkSize:    16,
+			logs:         "starting\n" + strings.Repeat("a", 40) + "\n",
+			expectChunks: []string{"starting\n", strings.Repeat("a", 16), strings.Repeat("a", 16), strings.Repeat("a", 8) + "\n"},
+		},
+		"truncated": {
+			maxBytes:        30,
+			expectChunks:    []string{"starting\npassword=hunter2\n"},
+			expectTruncated: true,
+		},
+	}
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			var chunks []*agent.LogsChunk
+			cmd := &logsCommand{
+				commandID: "logs",
+				namespace: "default",
+				container: "app",
+				maxBytes:  c.maxBytes,
+				chunkSize: c.chunkSize,
+				redact:    c.redact,
+				sentPod:   "quote-1",
+			}
+			input := c.logs
+			if input == "" {
+				input = logs
+			}
+			err := cmd.sendLogs(strings.NewReader(input), func(chunk *agent.LogsChunk) error {
+				chunks = append(chunks, chunk)
+				return nil
+			})
+			require.NoError(t, err)
+
+			require.Len(t, chunks, len(c.expectChunks))
+			for i, chunk := range chunks {
+				assert.Equal(t, c.expectChunks[i], string(chunk.Chunk))
+				assert.Equal(t, "logs", chunk.CommandId)
+				assert.Equal(t, "quote-1", chunk.Pod)
+				assert.Equal(t, "app", chunk.Container)
+				assert.Equal(t, c.expectTruncated && i == len(chunks)-1, chunk.Truncated)
+			}
+			assert.Equal(t, c.expectTruncated, cmd.truncated)
+		})
+	}
+}
+
+func newFakeLogsClient() *fake.Clientset {
+	now := time.Now()
+	labels := map[string]string{"app": "quote"}
+	pod := func(name string, phase apiv1.PodPhase, created time.Time) *apiv1.Pod {
+		return &apiv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				Labels:            labels,
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Status: apiv1.PodStatus{Phase: phase},
+		}
+	}
+	return fake.NewSimpleClientset(
+		&apiappsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "quote", Namespace: "default"},
+			Spec:       apiappsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: labels}},
+		},
+		&apiappsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "idle", Namespace: "default"},
+			Spec:       apiappsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "idle"}}},
+		},
+		pod("quote-old", apiv1.PodRunning, now.Add(-time.Hour)),
+		pod("quote-new", apiv1.PodRunning, now.Add(-time.Minute)),
+		pod("quote-pending", apiv1.PodPending, now),
+	)
+}
+
+func TestLogsCommand_deploymentPod(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeLogsClient()
+
+	cmd := &logsCommand{namespace: "default", deployment: "quote"}
+	pod, err := cmd.deploymentPod(ctx, client.CoreV1(), client.AppsV1())
+	require.NoError(t, err)
+	assert.Equal(t, "quote-new", pod)
+
+	cmd = &logsCommand{namespace: "default", deployment: "idle"}
+	_, err = cmd.deploymentPod(ctx, client.CoreV1(), client.AppsV1())
+	assert.EqualError(t, err, "deployment idle (default) has no running pods")
+}
+
+func TestHandleLogsDirective(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	client := &MockClient{}
+	a := newTestAgent(client)
+	a.Env = &Env{LogsRedact: []*regexp.Regexp{regexp.MustCompile("fake")}}
+	clientset := newFakeLogsClient()
+	dh := &BasicDirectiveHandler{
+		podsGetterFactory: func() (corev1.PodsGetter, error) {
+			return clientset.CoreV1(), nil
+		},
+		deploymentsGetterFactory: func() (appsv1.DeploymentsGetter, error) {
+			return clientset.AppsV1(), nil
+		},
+	}
+
+	dh.HandleDirective(ctx, a, &agent.Directive{ID: "one", Commands: []*agent.Command{
+		{LogsCommand: &agent.LogsCommand{
+			CommandId:  "logs",
+			Namespace:  "default",
+			Deployment: "quote",
+			TailLines:  100,
+		}},
+		{LogsCommand: &agent.LogsCommand{
+			CommandId:  "no pods",
+			Namespace:  "default",
+			Deployment: "idle",
+		}},
+	}})
+
+	results := client.GetResults()
+	require.Len(t, results, 2)
+	assert.Equal(t, "logs", results[0].CommandId)
+	assert.True(t, results[0].Success)
+	assert.Equal(t, "sent 31 bytes of logs of pod quote-new (default)", results[0].Message)
+	assert.Equal(t, "no pods", results[1].CommandId)
+	assert.False(t, results[1].Success)
+
+	// the fake client always returns "fake logs"
+	logs := client.GetLogs()
+	require.Len(t, logs, 1)
+	assert.Equal(t, "logs", logs[0].CommandId)
+	assert.Equal(t, "quote-new", logs[0].Pod)
+	assert.Equal(t, redactedLine, string(logs[0].Chunk))
+	assert.False(t, logs[0].Truncated)
+}
@@ -0,0 +1,94 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"time"
+
+	io_prometheus_client "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/datawire/ambassador-agent/pkg/api/agent"
+	"github.com/datawire/dlib/dlog"
+)
+
+// getEnvoyMetrics scrapes the Prometheus text exposition of Envoy's stats at
+// url and returns the metric families allowed by allowList, sorted by name.
+func getEnvoyMetrics(ctx context.Context, url *url.URL, allowList []string) ([]*io_prometheus_client.MetricFamily, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode > 299 {
+		return nil, fmt.Errorf("Cannot fetch metrics from url: %s. "+
+			"Response failed with status code: %d", url, resp.StatusCode)
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse metrics: %w", err)
+	}
+	return filterMetricFamilies(families, allowList), nil
+}
+
+// filterMetricFamilies returns the families whose name matches one of the
+// patterns in allowList, sorted by name. Patterns use path.Match syntax, e.g.
+// "envoy_cluster_upstream_rq*".
+func filterMetricFamilies(families map[string]*io_prometheus_client.MetricFamily, allowList []string) []*io_prometheus_client.MetricFamily {
+	allowed := make([]*io_prometheus_client.MetricFamily, 0)
+	for name, family := range families {
+		for _, pattern := range allowList {
+			if ok, _ := path.Match(pattern, name); ok {
+				allowed = append(allowed, family)
+				break
+			}
+		}
+	}
+	sort.Slice(allowed, func(i, j int) bool {
+		return allowed[i].GetName() < allowed[j].GetName()
+	})
+	return allowed
+}
+
+// ReportMetrics streams the allowed Envoy metrics to the Director, then waits
+// for the metrics report period before allowing the next report.
+func (a *Agent) ReportMetrics(ctx context.Context, metricsURL *url.URL) {
+	a.metricsReportRunning.Store(true) // Cleared when the metrics report completes
+
+	// Scraping and streaming can block, so we do this in a goroutine. Sleep
+	// after send, so we don't need to keep track of whether/when it's okay to
+	// send the next report.
+	go func(ctx context.Context, delay time.Duration, apikey string) {
+		if err := a.sendMetrics(ctx, metricsURL, apikey); err != nil {
+			dlog.Warnf(ctx, "failed to do metrics report: %+v", err)
+		}
+		dlog.Debugf(ctx, "Finished sending metrics report, sleeping for %s", delay.String())
+		time.Sleep(delay)
+		a.metricsReportRunning.Store(false)
+	}(ctx, a.metricsReportPeriod, a.AmbassadorAPIKey)
+}
+
+func (a *Agent) sendMetrics(ctx context.Context, metricsURL *url.URL, apiKey string) error {
+	families, err := getEnvoyMetrics(ctx, metricsURL, a.MetricsAllowList)
+	if err != nil {
+		return fmt.Errorf("error getting metrics from ambassador: %w", err)
+	}
+	if len(families) == 0 {
+		dlog.Debug(ctx, "No allowed metrics exist, not reporting metrics")
+		return nil
+	}
+	return a.comm.StreamMetrics(ctx, &agent.StreamMetricsMessage{
+		Identity:     a.agentID,
+		EnvoyMetrics: families,
+	}, apiKey)
+}
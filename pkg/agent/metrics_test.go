@@ -0,0 +1,117 @@
+package agent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/datawire/ambassador-agent/pkg/api/agent"
+	"github.com/datawire/dlib/dlog"
+)
+
+const envoyMetricsText = `# TYPE envoy_cluster_upstream_rq_total counter
+envoy_cluster_upstream_rq_total{envoy_cluster_name="cluster_backend"} 42
+# TYPE envoy_cluster_upstream_cx_active gauge
+envoy_cluster_upstream_cx_active{envoy_cluster_name="cluster_backend"} 3
+# TYPE envoy_server_uptime gauge
+envoy_server_uptime{} 1234
+# TYPE envoy_cluster_upstream_rq_time histogram
+envoy_cluster_upstream_rq_time_bucket{envoy_cluster_name="cluster_backend",le="0.5"} 10
+envoy_cluster_upstream_rq_time_bucket{envoy_cluster_name="cluster_backend",le="+Inf"} 12
+envoy_cluster_upstream_rq_time_sum{envoy_cluster_name="cluster_backend"} 7.5
+envoy_cluster_upstream_rq_time_count{envoy_cluster_name="cluster_backend"} 12
+`
+
+func newEnvoyMetricsServer(t *testing.T) *url.URL {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(envoyMetricsText))
+	}))
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL + "/metrics")
+	require.NoError(t, err)
+	return u
+}
+
+func TestGetEnvoyMetrics(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	metricsURL := newEnvoyMetricsServer(t)
+
+	type testcase struct {
+		allowList []string
+		expected  []string
+	}
+	cases := map[string]testcase{
+		"exact names": {
+			allowList: []string{"envoy_cluster_upstream_rq_total", "envoy_server_uptime"},
+			expected:  []string{"envoy_cluster_upstream_rq_total", "envoy_server_uptime"},
+		},
+		"wildcard": {
+			allowList: []string{"envoy_cluster_upstream_*"},
+			expected:  []string{"envoy_cluster_upstream_cx_active", "envoy_cluster_upstream_rq_time", "envoy_cluster_upstream_rq_total"},
+		},
+		"nothing allowed": {
+			expected: []string{},
+		},
+	}
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			families, err := getEnvoyMetrics(ctx, metricsURL, c.allowList)
+			require.NoError(t, err)
+			names := make([]string, len(families))
+			for i, f := range families {
+				names[i] = f.GetName()
+			}
+			assert.Equal(t, c.expected, names)
+		})
+	}
+
+	families, err := getEnvoyMetrics(ctx, metricsURL, []string{"envoy_cluster_upstream_rq_time"})
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	require.Len(t, families[0].Metric, 1)
+	histogram := families[0].Metric[0].GetHistogram()
+	require.NotNil(t, histogram)
+	assert.Equal(t, uint64(12), histogram.GetSampleCount())
+	assert.Equal(t, 7.5, histogram.GetSampleSum())
+}
+
+func TestReportMetrics(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	client := &MockClient{}
+	a := newTestAgent(client)
+	a.Env = &Env{MetricsAllowList: []string{"envoy_cluster_upstream_rq_total"}}
+	a.agentID = &agent.Identity{ClusterId: "cluster"}
+
+	dh := &BasicDirectiveHandler{DefaultMinReportPeriod: time.Millisecond}
+	dh.HandleDirective(ctx, a, &agent.Directive{ID: "one", MetricsReportPeriod: durationpb.New(time.Millisecond)})
+	assert.Equal(t, time.Millisecond, a.metricsReportPeriod)
+
+	a.ReportMetrics(ctx, newEnvoyMetricsServer(t))
+	require.Eventually(t, func() bool {
+		return !a.metricsReportRunning.Load()
+	}, 5*time.Second, 10*time.Millisecond)
+
+	sent := client.GetMetrics()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "cluster", sent[0].Identity.ClusterId)
+	require.Len(t, sent[0].EnvoyMetrics, 1)
+	assert.Equal(t, "envoy_cluster_upstream_rq_total", sent[0].EnvoyMetrics[0].GetName())
+	assert.Equal(t, 42.0, sent[0].EnvoyMetrics[0].Metric[0].GetCounter().GetValue())
+
+	// a zero period stops metrics reporting, no period leaves it alone
+	dh.HandleDirective(ctx, a, &agent.Directive{ID: "two"})
+	assert.Equal(t, time.Millisecond, a.metricsReportPeriod)
+	dh.HandleDirective(ctx, a, &agent.Directive{ID: "three", MetricsReportPeriod: durationpb.New(0)})
+	assert.Zero(t, a.metricsReportPeriod)
+}
@@ -0,0 +1,176 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// policyEffect tells whether a policy rule allows or denies commands.
+type policyEffect string
+
+const (
+	policyEffectAllow = policyEffect("allow")
+	policyEffectDeny  = policyEffect("deny")
+)
+
+// commandPolicy restricts the commands that the Director may run. Rules are
+// evaluated in order and the first one that matches a command decides; the
+// default decides when none does. For example:
+//
+//	default: deny
+//	rules:
+//	- effect: allow
+//	  commands: [rollout, deployment]
+//	  namespaces: [team-*]
+//	- effect: allow
+//	  commands: [secret-sync]
+//	  namespaces: [ambassador]
+//	  names: [api-keys-*]
+//	  actions: [SET]
+type commandPolicy struct {
+	// Default is the effect when no rule matches, deny if not set.
+	Default policyEffect        `json:"default,omitempty"`
+	Rules   []commandPolicyRule `json:"rules,omitempty"`
+}
+
+// commandPolicyRule matches commands on all of its non-empty criteria.
+// Namespaces and names are globs, as understood by path.Match.
+type commandPolicyRule struct {
+	Effect     policyEffect  `json:"effect"`
+	Commands   []commandKind `json:"commands,omitempty"`
+	Namespaces []string      `json:"namespaces,omitempty"`
+	Names      []string      `json:"names,omitempty"`
+	Actions    []string      `json:"actions,omitempty"`
+}
+
+func parseCommandPolicy(data []byte) (*commandPolicy, error) {
+	var p commandPolicy
+	if err := yaml.UnmarshalStrict(data, &p); err != nil {
+		return nil, err
+	}
+	if err := p.Default.validate(true); err != nil {
+		return nil, fmt.Errorf("default: %w", err)
+	}
+	for i, rule := range p.Rules {
+		if err := rule.Effect.validate(false); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		for _, kind := range rule.Commands {
+			if !kind.valid() {
+				return nil, fmt.Errorf("rule %d: unknown command %q", i+1, kind)
+			}
+		}
+		for _, pattern := range append(append([]string(nil), rule.Namespaces...), rule.Names...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("rule %d: invalid pattern %q: %w", i+1, pattern, err)
+			}
+		}
+	}
+	return &p, nil
+}
+
+func (e policyEffect) validate(optional bool) error {
+	switch {
+	case e == policyEffectAllow || e == policyEffectDeny:
+		return nil
+	case e == "" && optional:
+		return nil
+	default:
+		return fmt.Errorf("invalid effect %q, must be %s or %s", e, policyEffectAllow, policyEffectDeny)
+	}
+}
+
+// Evaluate tells whether the command is allowed, and why when it isn't.
+func (p *commandPolicy) Evaluate(target commandTarget) (bool, string) {
+	for i, rule := range p.Rules {
+		if rule.matches(target) {
+			if rule.Effect == policyEffectAllow {
+				return true, ""
+			}
+			return false, fmt.Sprintf("denied by rule %d of the command policy", i+1)
+		}
+	}
+	if p.Default == policyEffectAllow {
+		return true, ""
+	}
+	return false, "denied by the command policy, no rule allows it"
+}
+
+func (r *commandPolicyRule) matches(target commandTarget) bool {
+	return matchesAny(r.Commands, func(kind commandKind) bool { return kind == target.kind }) &&
+		matchesAny(r.Namespaces, func(pattern string) bool { return globMatch(pattern, target.namespace) }) &&
+		matchesAny(r.Names, func(pattern string) bool { return globMatch(pattern, target.name) }) &&
+		matchesAny(r.Actions, func(action string) bool { return action == target.action })
+}
+
+// matchesAny tells whether any of the criteria match. No criteria match everything.
+func matchesAny[T any](criteria []T, match func(T) bool) bool {
+	if len(criteria) == 0 {
+		return true
+	}
+	for _, c := range criteria {
+		if match(c) {
+			return true
+		}
+	}
+	return false
+}
+
+func globMatch(pattern, str string) bool {
+	ok, _ := path.Match(pattern, str)
+	return ok
+}
+
+// commandPolicyFile is a command policy read from a file, typically a mounted
+// ConfigMap, and read again when the file changes. Everything is denied while
+// the file can't be read or parsed.
+type commandPolicyFile struct {
+	path string
+
+	mu      sync.Mutex
+	policy  *commandPolicy
+	err     error
+	modTime time.Time
+}
+
+func newCommandPolicyFile(path string) *commandPolicyFile {
+	return &commandPolicyFile{path: path}
+}
+
+// Evaluate tells whether the current policy allows the command, and why when it doesn't.
+func (f *commandPolicyFile) Evaluate(target commandTarget) (bool, string) {
+	policy, err := f.load()
+	if err != nil {
+		return false, fmt.Sprintf("denied because the command policy is invalid: %v", err)
+	}
+	return policy.Evaluate(target)
+}
+
+func (f *commandPolicyFile) load() (*commandPolicy, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return nil, err
+	}
+	if (f.policy != nil || f.err != nil) && info.ModTime().Equal(f.modTime) {
+		return f.policy, f.err
+	}
+	f.modTime = info.ModTime()
+	data, err := os.ReadFile(f.path)
+	if err == nil {
+		f.policy, err = parseCommandPolicy(data)
+	}
+	if err != nil {
+		f.policy, f.err = nil, fmt.Errorf("%s: %w", f.path, err)
+	} else {
+		f.err = nil
+	}
+	return f.policy, f.err
+}
@@ -0,0 +1,172 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiappsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	appsv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+
+	"github.com/datawire/ambassador-agent/pkg/api/agent"
+	"github.com/datawire/dlib/dlog"
+)
+
+const testPolicy = `
+rules:
+- effect: deny
+  commands: [secret-sync]
+  names: [admin-*]
+- effect: allow
+  commands: [secret-sync, deployment]
+  namespaces: [team-*]
+- effect: allow
+  commands: [rollout]
+  actions: [PAUSE, RESUME]
+`
+
+func TestParseCommandPolicy(t *testing.T) {
+	p, err := parseCommandPolicy([]byte(testPolicy))
+	require.NoError(t, err)
+	assert.Len(t, p.Rules, 3)
+
+	for name, data := range map[string]string{
+		"unknown field":   "rules:\n- effect: allow\n  kinds: [rollout]\n",
+		"invalid default": "default: maybe\n",
+		"missing effect":  "rules:\n- commands: [rollout]\n",
+		"unknown command": "rules:\n- effect: allow\n  commands: [pod]\n",
+		"invalid glob":    "rules:\n- effect: allow\n  names: ['[']\n",
+	} {
+		_, err := parseCommandPolicy([]byte(data))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestCommandPolicy_Evaluate(t *testing.T) {
+	p, err := parseCommandPolicy([]byte(testPolicy))
+	require.NoError(t, err)
+
+	type testcase struct {
+		target commandTarget
+		allow  bool
+		reason string
+	}
+	cases := map[string]testcase{
+		"allowed by namespace glob": {
+			target: commandTarget{kind: commandKindSecretSync, namespace: "team-a", name: "api-keys", action: "SET"},
+			allow:  true,
+		},
+		"first matching rule wins": {
+			target: commandTarget{kind: commandKindSecretSync, namespace: "team-a", name: "admin-token", action: "SET"},
+			reason: "denied by rule 1 of the command policy",
+		},
+		"namespace not allowed": {
+			target: commandTarget{kind: commandKindDeployment, namespace: "kube-system", name: "coredns", action: "RESTART"},
+			reason: "denied by the command policy, no rule allows it",
+		},
+		"allowed action": {
+			target: commandTarget{kind: commandKindRollout, namespace: "default", name: "web", action: "PAUSE"},
+			allow:  true,
+		},
+		"action not allowed": {
+			target: commandTarget{kind: commandKindRollout, namespace: "default", name: "web", action: "SET_IMAGE"},
+			reason: "denied by the command policy, no rule allows it",
+		},
+	}
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			allow, reason := p.Evaluate(c.target)
+			assert.Equal(t, c.allow, allow)
+			assert.Equal(t, c.reason, reason)
+		})
+	}
+
+	p.Default = policyEffectAllow
+	allow, _ := p.Evaluate(commandTarget{kind: commandKindApplication, namespace: "argocd", name: "guestbook", action: "SYNC"})
+	assert.True(t, allow)
+}
+
+func TestCommandPolicyFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "policy.yaml")
+	f := newCommandPolicyFile(file)
+	target := commandTarget{kind: commandKindDeployment, namespace: "default", name: "quote", action: "RESTART"}
+
+	allow, reason := f.Evaluate(target)
+	assert.False(t, allow, "denied while the file is missing")
+	assert.Contains(t, reason, "denied because the command policy is invalid")
+
+	require.NoError(t, os.WriteFile(file, []byte("default: allow\n"), 0o600))
+	allow, _ = f.Evaluate(target)
+	assert.True(t, allow)
+
+	// the file is read again when it changes
+	require.NoError(t, os.WriteFile(file, []byte("default: deny\n"), 0o600))
+	require.NoError(t, os.Chtimes(file, time.Now(), time.Now().Add(time.Minute)))
+	allow, reason = f.Evaluate(target)
+	assert.False(t, allow)
+	assert.Equal(t, "denied by the command policy, no rule allows it", reason)
+
+	require.NoError(t, os.WriteFile(file, []byte("default: [\n"), 0o600))
+	require.NoError(t, os.Chtimes(file, time.Now(), time.Now().Add(2*time.Minute)))
+	allow, reason = f.Evaluate(target)
+	assert.False(t, allow, "denied while the file is invalid")
+	assert.Contains(t, reason, "denied because the command policy is invalid")
+}
+
+func TestHandleDirective_CommandPolicy(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	file := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(testPolicy), 0o600))
+
+	client := &MockClient{}
+	a := newTestAgent(client)
+	clientset := fake.NewSimpleClientset(
+		&apiappsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "quote", Namespace: "team-a"}},
+		&apiappsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: "kube-system"}},
+	)
+	dh := &BasicDirectiveHandler{
+		deploymentsGetterFactory: func() (appsv1.DeploymentsGetter, error) { return clientset.AppsV1(), nil },
+		policy:                   newCommandPolicyFile(file),
+	}
+	denied := testutil.ToFloat64(commandsDenied.WithLabelValues(string(commandKindDeployment)))
+
+	dh.HandleDirective(ctx, a, &agent.Directive{ID: "one", Commands: []*agent.Command{
+		{DeploymentCommand: &agent.DeploymentCommand{
+			CommandId: "allowed",
+			Name:      "quote",
+			Namespace: "team-a",
+			Action:    agent.DeploymentCommand_RESTART,
+		}},
+		{DeploymentCommand: &agent.DeploymentCommand{
+			CommandId: "denied",
+			Name:      "coredns",
+			Namespace: "kube-system",
+			Action:    agent.DeploymentCommand_RESTART,
+		}},
+	}})
+
+	results := client.GetResults()
+	require.Len(t, results, 2)
+	assert.Equal(t, "allowed", results[0].CommandId)
+	assert.True(t, results[0].Success)
+	assert.Equal(t, "denied", results[1].CommandId)
+	assert.False(t, results[1].Success)
+	assert.Equal(t, agent.CommandResult_FAILED, results[1].Status)
+	assert.Equal(t, "denied by the command policy, no rule allows it", results[1].Message)
+	assert.Equal(t, denied+1, testutil.ToFloat64(commandsDenied.WithLabelValues(string(commandKindDeployment))))
+
+	deployment, err := clientset.AppsV1().Deployments("kube-system").Get(ctx, "coredns", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, deployment.Spec.Template.Annotations, "denied command did not run")
+}
@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// proxyFunc returns the proxy to use for the given URL, or nil when the URL
+// must be reached directly.
+type proxyFunc func(*url.URL) (*url.URL, error)
+
+// directorProxyFunc returns the proxyFunc for the connection to the Director.
+// An explicit proxyURL is always used; otherwise HTTPS_PROXY, HTTP_PROXY and
+// NO_PROXY are honored.
+func directorProxyFunc(proxyURL *url.URL) proxyFunc {
+	if proxyURL != nil {
+		return func(*url.URL) (*url.URL, error) { return proxyURL, nil }
+	}
+	return httpproxy.FromEnvironment().ProxyFunc()
+}
+
+// proxyDialer returns a dialer that reaches its address through an HTTP
+// CONNECT tunnel when proxyFor returns a proxy for it, and directly otherwise.
+func proxyDialer(proxyFor proxyFunc, secure bool) func(context.Context, string) (net.Conn, error) {
+	scheme := "http"
+	if secure {
+		scheme = "https"
+	}
+	return func(ctx context.Context, addr string) (net.Conn, error) {
+		proxy, err := proxyFor(&url.URL{Scheme: scheme, Host: addr})
+		if err != nil {
+			return nil, fmt.Errorf("unable to determine proxy for %s: %w", addr, err)
+		}
+		if proxy == nil {
+			return (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+		}
+		return dialConnect(ctx, proxy, addr)
+	}
+}
+
+// dialConnect opens a tunnel to addr through the proxy using the HTTP CONNECT
+// method, authenticating with the user info of the proxy URL if any.
+func dialConnect(ctx context.Context, proxy *url.URL, addr string) (_ net.Conn, err error) {
+	proxyAddr := proxy.Host
+	if proxy.Port() == "" {
+		port := "80"
+		if proxy.Scheme == "https" {
+			port = "443"
+		}
+		proxyAddr = net.JoinHostPort(proxy.Hostname(), port)
+	}
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, fmt.Errorf("unable to dial proxy %s: %w", proxyAddr, err)
+	}
+	defer func() {
+		if err != nil {
+			conn.Close()
+		}
+	}()
+	if proxy.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: proxy.Hostname(), MinVersion: tls.VersionTLS12})
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			return nil, fmt.Errorf("TLS handshake with proxy %s: %w", proxyAddr, err)
+		}
+		conn = tlsConn
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer func() { _ = conn.SetDeadline(time.Time{}) }()
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if u := proxy.User; u != nil {
+		password, _ := u.Password()
+		auth := base64.StdEncoding.EncodeToString([]byte(u.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+auth)
+	}
+	if err = req.Write(conn); err != nil {
+		return nil, fmt.Errorf("unable to send CONNECT to proxy %s: %w", proxyAddr, err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read CONNECT response from proxy %s: %w", proxyAddr, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("proxy %s refused CONNECT to %s: %s", proxyAddr, addr, resp.Status)
+	}
+	if br.Buffered() > 0 {
+		// The server already sent something through the tunnel
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn is a net.Conn that first returns what was read ahead into r.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
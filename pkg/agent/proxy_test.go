@@ -0,0 +1,157 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/datawire/ambassador-agent/pkg/api/agent"
+	"github.com/datawire/dlib/dlog"
+)
+
+// connectProxy is an in-process stand-in for an HTTP CONNECT forward proxy.
+type connectProxy struct {
+	ln   net.Listener
+	auth string // expected Proxy-Authorization, if any
+
+	mu      sync.Mutex
+	tunnels []string
+}
+
+func newConnectProxy(t *testing.T, user, password string) *connectProxy {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	p := &connectProxy{ln: ln}
+	if user != "" {
+		p.auth = "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+	}
+	t.Cleanup(func() { ln.Close() })
+	go p.serve()
+	return p
+}
+
+func (p *connectProxy) serve() {
+	for {
+		conn, err := p.ln.Accept()
+		if err != nil {
+			return
+		}
+		go p.handle(conn)
+	}
+}
+
+func (p *connectProxy) handle(conn net.Conn) {
+	defer conn.Close()
+	req, err := http.ReadRequest(bufio.NewReader(conn))
+	if err != nil {
+		return
+	}
+	if req.Method != http.MethodConnect {
+		_, _ = io.WriteString(conn, "HTTP/1.1 405 Method Not Allowed\r\n\r\n")
+		return
+	}
+	if p.auth != "" && req.Header.Get("Proxy-Authorization") != p.auth {
+		_, _ = io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+		return
+	}
+	target, err := net.Dial("tcp", req.Host)
+	if err != nil {
+		_, _ = io.WriteString(conn, "HTTP/1.1 502 Bad Gateway\r\n\r\n")
+		return
+	}
+	defer target.Close()
+	p.mu.Lock()
+	p.tunnels = append(p.tunnels, req.Host)
+	p.mu.Unlock()
+
+	_, _ = io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+	go func() { _, _ = io.Copy(target, conn) }()
+	_, _ = io.Copy(conn, target)
+}
+
+func (p *connectProxy) Tunnels() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.tunnels...)
+}
+
+func (p *connectProxy) URL(user, password string) *url.URL {
+	u := &url.URL{Scheme: "http", Host: p.ln.Addr().String()}
+	if user != "" {
+		u.User = url.UserPassword(user, password)
+	}
+	return u
+}
+
+type resultDirector struct {
+	agent.UnimplementedDirectorServer
+}
+
+func (resultDirector) ReportCommandResult(context.Context, *agent.CommandResult) (*agent.CommandResultResponse, error) {
+	return &agent.CommandResultResponse{}, nil
+}
+
+func newTestDirector(t *testing.T) *ConnInfo {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	agent.RegisterDirectorServer(srv, resultDirector{})
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(srv.Stop)
+	host, port, err := net.SplitHostPort(ln.Addr().String())
+	require.NoError(t, err)
+	return &ConnInfo{hostname: host, port: port, secure: false}
+}
+
+func TestCommThroughProxy(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	connInfo := newTestDirector(t)
+	proxy := newConnectProxy(t, "agent", "s3cr3t")
+
+	t.Run("authenticated", func(t *testing.T) {
+		c, err := NewComm(ctx, connInfo, nil, proxy.URL("agent", "s3cr3t"), &agent.Identity{}, "apikey", nil)
+		require.NoError(t, err)
+		defer c.Close()
+
+		require.NoError(t, c.ReportCommandResult(ctx, &agent.CommandResult{CommandId: "1"}, "apikey"))
+		assert.Contains(t, proxy.Tunnels(), net.JoinHostPort(connInfo.hostname, connInfo.port))
+	})
+	t.Run("wrong credentials", func(t *testing.T) {
+		c, err := NewComm(ctx, connInfo, nil, proxy.URL("agent", "wrong"), &agent.Identity{}, "apikey", nil)
+		require.NoError(t, err)
+		defer c.Close()
+
+		assert.Error(t, c.ReportCommandResult(ctx, &agent.CommandResult{CommandId: "2"}, "apikey"))
+	})
+}
+
+func TestDirectorProxyFunc(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "http://proxy.example.com:3128")
+	t.Setenv("NO_PROXY", "director.internal")
+
+	proxyFor := directorProxyFunc(nil)
+	proxy, err := proxyFor(&url.URL{Scheme: "https", Host: "app.getambassador.io:443"})
+	require.NoError(t, err)
+	require.NotNil(t, proxy)
+	assert.Equal(t, "proxy.example.com:3128", proxy.Host)
+
+	proxy, err = proxyFor(&url.URL{Scheme: "https", Host: "director.internal:443"})
+	require.NoError(t, err)
+	assert.Nil(t, proxy)
+
+	// an explicit proxy wins over the environment
+	explicit := &url.URL{Scheme: "http", Host: "explicit:8080"}
+	proxy, err = directorProxyFunc(explicit)(&url.URL{Scheme: "https", Host: "director.internal:443"})
+	require.NoError(t, err)
+	assert.Equal(t, explicit, proxy)
+}
@@ -0,0 +1,296 @@
+package agent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/datawire/ambassador-agent/pkg/agent/watchers"
+	"github.com/datawire/dlib/dlog"
+)
+
+// redactLabel opts the objects that have it set to "false" out of the snapshot
+// redaction rules.
+const redactLabel = "getambassador.io/agent-redact"
+
+// redactAction tells what a redaction rule does to the fields it matches.
+type redactAction string
+
+const (
+	// redactActionDrop removes the fields from the snapshot.
+	redactActionDrop = redactAction("drop")
+	// redactActionHash replaces the strings in the fields with their SHA-256 hash, so that
+	// changes can still be seen. Other values are replaced with null.
+	redactActionHash = redactAction("hash")
+)
+
+// redactPathSegment is a field of a redaction rule path, and whether the field is a
+// list whose elements the rest of the path applies to.
+type redactPathSegment struct {
+	field string
+	list  bool
+}
+
+// redactRule is an entry of AGENT_SNAPSHOT_REDACT.
+type redactRule struct {
+	action redactAction
+	kind   string
+	path   []redactPathSegment
+}
+
+// parseSnapshotRedact parses redaction rules of the form [action:]Kind.field[[]].field...,
+// e.g. "ConfigMap.data" or "hash:Pod.spec.containers[].env[].value". The fields are the
+// ones of the JSON of the objects, and the action is drop when none is given.
+func parseSnapshotRedact(entries []string) ([]redactRule, error) {
+	rules := make([]redactRule, 0, len(entries))
+	for _, entry := range entries {
+		rule := redactRule{action: redactActionDrop}
+		path := entry
+		if action, rest, ok := strings.Cut(entry, ":"); ok {
+			rule.action, path = redactAction(action), rest
+		}
+		if rule.action != redactActionDrop && rule.action != redactActionHash {
+			return nil, fmt.Errorf("invalid snapshot redaction rule %q, the action must be drop or hash", entry)
+		}
+		fields := strings.Split(path, ".")
+		if len(fields) < 2 || fields[0] == "" {
+			return nil, fmt.Errorf("invalid snapshot redaction rule %q, must be [action:]Kind.field[[]].field...", entry)
+		}
+		rule.kind = fields[0]
+		for _, field := range fields[1:] {
+			seg := redactPathSegment{field: field}
+			if strings.HasSuffix(field, "[]") {
+				seg.field, seg.list = strings.TrimSuffix(field, "[]"), true
+			}
+			if seg.field == "" || strings.ContainsAny(seg.field, "[]") {
+				return nil, fmt.Errorf("invalid snapshot redaction rule %q, must be [action:]Kind.field[[]].field...", entry)
+			}
+			rule.path = append(rule.path, seg)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// redactSnapshot returns a copy of the snapshot with the rules applied to the objects
+// of its Kubernetes section. The snapshot is left as is, so that the agent still finds
+// what it needs in it, and so are its objects and lists, which may be shared with the
+// watchers: the objects that are redacted are replaced by redacted copies, in copies of
+// their lists.
+func redactSnapshot(ctx context.Context, snapshot *watchers.Snapshot, rules []redactRule) *watchers.Snapshot {
+	if snapshot.Kubernetes == nil || len(rules) == 0 {
+		return snapshot
+	}
+	ksn := *snapshot.Kubernetes
+	if ksn.KubernetesSnapshot != nil {
+		emissary := *ksn.KubernetesSnapshot
+		ksn.KubernetesSnapshot = &emissary
+	}
+	redactStruct(ctx, reflect.ValueOf(&ksn).Elem(), rules)
+	return &watchers.Snapshot{Snapshot: snapshot.Snapshot, Kubernetes: &ksn}
+}
+
+// redactStruct redacts the lists of objects in the fields of the struct, and in the
+// structs that it embeds.
+func redactStruct(ctx context.Context, v reflect.Value, rules []redactRule) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf, field := t.Field(i), v.Field(i)
+		if !sf.IsExported() || sf.Tag.Get("json") == "-" {
+			continue
+		}
+		switch {
+		case sf.Anonymous && field.Kind() == reflect.Ptr && field.Type().Elem().Kind() == reflect.Struct:
+			if !field.IsNil() {
+				redactStruct(ctx, field.Elem(), rules)
+			}
+		case field.Kind() == reflect.Slice:
+			if redacted, ok := redactList(ctx, field, rules); ok {
+				field.Set(redacted)
+			}
+		case field.Kind() == reflect.Map && field.Type().Elem().Kind() == reflect.Slice:
+			redactMapOfLists(ctx, field, rules)
+		}
+	}
+}
+
+// redactMapOfLists redacts the lists of objects of the map, like the ExtraResources.
+func redactMapOfLists(ctx context.Context, m reflect.Value, rules []redactRule) {
+	if m.IsNil() {
+		return
+	}
+	var redactedMap reflect.Value
+	iter := m.MapRange()
+	for iter.Next() {
+		redacted, ok := redactList(ctx, iter.Value(), rules)
+		if !ok {
+			continue
+		}
+		if !redactedMap.IsValid() {
+			redactedMap = reflect.MakeMapWithSize(m.Type(), m.Len())
+			copyIter := m.MapRange()
+			for copyIter.Next() {
+				redactedMap.SetMapIndex(copyIter.Key(), copyIter.Value())
+			}
+		}
+		redactedMap.SetMapIndex(iter.Key(), redacted)
+	}
+	if redactedMap.IsValid() {
+		m.Set(redactedMap)
+	}
+}
+
+// redactList returns a copy of the list with the objects that the rules apply to
+// redacted, or false when none of them were.
+func redactList(ctx context.Context, list reflect.Value, rules []redactRule) (reflect.Value, bool) {
+	elemKind := list.Type().Elem().Kind()
+	if elemKind != reflect.Ptr && elemKind != reflect.Interface {
+		return reflect.Value{}, false
+	}
+	var redacted reflect.Value
+	for i := 0; i < list.Len(); i++ {
+		elem := list.Index(i)
+		obj := elem
+		if elemKind == reflect.Interface {
+			obj = elem.Elem()
+		}
+		redactedObj, changed, err := redactObject(obj, rules)
+		if err != nil {
+			// better leave the object out than report what should have been redacted
+			dlog.Errorf(ctx, "Error redacting %s, it is left out of the snapshot: %v", obj.Type(), err)
+		}
+		if !changed && err == nil && !redacted.IsValid() {
+			continue
+		}
+		if !redacted.IsValid() {
+			redacted = reflect.AppendSlice(reflect.MakeSlice(list.Type(), 0, list.Len()), list.Slice(0, i))
+		}
+		switch {
+		case err != nil:
+		case changed:
+			redacted = reflect.Append(redacted, redactedObj)
+		default:
+			redacted = reflect.Append(redacted, elem)
+		}
+	}
+	return redacted, redacted.IsValid()
+}
+
+// redactObject returns a redacted copy of the object, or false when none of the rules
+// apply to it. The object must be a pointer to a struct that can be encoded in JSON.
+func redactObject(obj reflect.Value, rules []redactRule) (reflect.Value, bool, error) {
+	if obj.Kind() != reflect.Ptr || obj.IsNil() || obj.Type().Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, false, nil
+	}
+	// the rules name the kind of the objects, or their type, as the kind isn't always set
+	typeName, kind := obj.Type().Elem().Name(), ""
+	if ro, ok := obj.Interface().(runtime.Object); ok {
+		kind = ro.GetObjectKind().GroupVersionKind().Kind
+	}
+	var matching []redactRule
+	for _, rule := range rules {
+		if rule.kind == typeName || rule.kind == kind {
+			matching = append(matching, rule)
+		}
+	}
+	if len(matching) == 0 {
+		return reflect.Value{}, false, nil
+	}
+
+	data, err := json.Marshal(obj.Interface())
+	if err != nil {
+		return reflect.Value{}, false, err
+	}
+	var u map[string]any
+	if err := json.Unmarshal(data, &u); err != nil {
+		return reflect.Value{}, false, err
+	}
+	if redactOptOut(u) {
+		return reflect.Value{}, false, nil
+	}
+
+	changed := false
+	for _, rule := range matching {
+		if redactPath(u, rule.path, rule.action) {
+			changed = true
+		}
+	}
+	if !changed {
+		return reflect.Value{}, false, nil
+	}
+	if data, err = json.Marshal(u); err != nil {
+		return reflect.Value{}, false, err
+	}
+	redacted := reflect.New(obj.Type().Elem())
+	if err := json.Unmarshal(data, redacted.Interface()); err != nil {
+		return reflect.Value{}, false, err
+	}
+	return redacted, true, nil
+}
+
+// redactOptOut tells whether the JSON object has the redactLabel set to "false".
+func redactOptOut(u map[string]any) bool {
+	metadata, _ := u["metadata"].(map[string]any)
+	labels, _ := metadata["labels"].(map[string]any)
+	return labels[redactLabel] == "false"
+}
+
+// redactPath applies the action to the fields of the JSON object at the path, and
+// tells whether there were any.
+func redactPath(u map[string]any, path []redactPathSegment, action redactAction) bool {
+	seg := path[0]
+	value, ok := u[seg.field]
+	if !ok || value == nil {
+		return false
+	}
+	if len(path) == 1 {
+		if action == redactActionHash {
+			u[seg.field] = redactHash(value)
+		} else {
+			delete(u, seg.field)
+		}
+		return true
+	}
+
+	var objs []any
+	if seg.list {
+		objs, _ = value.([]any)
+	} else {
+		objs = []any{value}
+	}
+	changed := false
+	for _, obj := range objs {
+		if m, ok := obj.(map[string]any); ok && redactPath(m, path[1:], action) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// redactHash replaces the strings in the JSON value with their SHA-256 hash, and the
+// other scalars with null.
+func redactHash(value any) any {
+	switch value := value.(type) {
+	case string:
+		sum := sha256.Sum256([]byte(value))
+		return "sha256:" + hex.EncodeToString(sum[:])
+	case map[string]any:
+		for k, v := range value {
+			value[k] = redactHash(v)
+		}
+		return value
+	case []any:
+		for i, v := range value {
+			value[i] = redactHash(v)
+		}
+		return value
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,146 @@
+package agent
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	rpc "github.com/datawire/ambassador-agent/rpc/agent"
+	"github.com/datawire/dlib/dlog"
+	snapshotTypes "github.com/emissary-ingress/emissary/v3/pkg/snapshot/v1"
+)
+
+func TestParseSnapshotRedact(t *testing.T) {
+	rules, err := parseSnapshotRedact([]string{"ConfigMap.data", "hash:Pod.spec.containers[].env[].value"})
+	require.NoError(t, err)
+	assert.Equal(t, []redactRule{
+		{action: redactActionDrop, kind: "ConfigMap", path: []redactPathSegment{{field: "data"}}},
+		{action: redactActionHash, kind: "Pod", path: []redactPathSegment{
+			{field: "spec"}, {field: "containers", list: true}, {field: "env", list: true}, {field: "value"},
+		}},
+	}, rules)
+
+	for _, entry := range []string{"ConfigMap", ".data", "erase:ConfigMap.data", "Pod.spec..containers", "Pod.spec.containers[0]"} {
+		_, err := parseSnapshotRedact([]string{entry})
+		assert.Error(t, err, entry)
+	}
+}
+
+func TestProcessSnapshot_Redact(t *testing.T) {
+	te := newTestEnv("AGENT_SNAPSHOT_REDACT", strings.Join([]string{
+		"ConfigMap.data",
+		"hash:Pod.spec.containers[].env[].value",
+		"Certificate.spec.secretName",
+	}, " "))
+	env, err := LoadEnv(te.lookup)
+	require.NoError(t, err)
+
+	cm := func(name string, labels map[string]string) *core.ConfigMap {
+		return &core.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels},
+			Data:       map[string]string{"password": "hunter2"},
+		}
+	}
+	configMaps := []*core.ConfigMap{cm("app", nil), cm("public", map[string]string{redactLabel: "false"})}
+	pod := &core.Pod{
+		TypeMeta:   metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec: core.PodSpec{Containers: []core.Container{{
+			Name:  "app",
+			Image: "app:1.0",
+			Env: []core.EnvVar{
+				{Name: "PASSWORD", Value: "hunter2"},
+				{Name: "FROM_SECRET", ValueFrom: &core.EnvVarSource{SecretKeyRef: &core.SecretKeySelector{Key: "password"}}},
+			},
+		}}},
+	}
+	cert := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Certificate",
+		"metadata":   map[string]any{"name": "app", "namespace": "default"},
+		"spec":       map[string]any{"secretName": "app-tls", "dnsNames": []any{"app.example.com"}},
+	}}
+
+	// the Gateway API and unstructured resources are redacted by kind too
+	a := &Agent{Env: env, gatewayAPI: &gatewayAPIStore{}}
+	require.NoError(t, a.gatewayAPI.gateways.FromCallback(&GenericCallback{Sotw: []interface{}{cert}}))
+	snapshot := &snapshotTypes.Snapshot{
+		AmbassadorMeta: &snapshotTypes.AmbassadorMetaInfo{ClusterID: "redact"},
+		Kubernetes: &snapshotTypes.KubernetesSnapshot{
+			ConfigMaps:       configMaps,
+			Pods:             []*core.Pod{pod},
+			KNativeIngresses: []*unstructured.Unstructured{cert},
+		},
+	}
+	ctx := dlog.NewTestContext(t, false)
+	require.NoError(t, a.ProcessSnapshot(ctx, snapshot))
+	require.NotNil(t, a.reportToSend)
+
+	var reported struct {
+		Kubernetes struct {
+			ConfigMaps []*core.ConfigMap
+			Pods       []*core.Pod
+			Gateways   []map[string]any `json:"gateways.gateway.networking.k8s.io"`
+			KNative    []map[string]any `json:"ingresses.networking.internal.knative.dev"`
+		}
+	}
+	require.NoError(t, json.Unmarshal(a.reportToSend.RawSnapshot, &reported))
+
+	require.Len(t, reported.Kubernetes.ConfigMaps, 2)
+	assert.Equal(t, "app", reported.Kubernetes.ConfigMaps[0].Name)
+	assert.Nil(t, reported.Kubernetes.ConfigMaps[0].Data)
+	assert.Equal(t, "public", reported.Kubernetes.ConfigMaps[1].Name)
+	assert.Equal(t, map[string]string{"password": "hunter2"}, reported.Kubernetes.ConfigMaps[1].Data)
+
+	require.Len(t, reported.Kubernetes.Pods, 1)
+	env0 := reported.Kubernetes.Pods[0].Spec.Containers[0].Env
+	assert.Equal(t, "PASSWORD", env0[0].Name)
+	assert.Equal(t, "sha256:f52fbd32b2b3b86ff88ef6c490628285f482af15ddcb29541f94bcf526a3f6c7", env0[0].Value)
+	assert.Equal(t, "password", env0[1].ValueFrom.SecretKeyRef.Key)
+	assert.Equal(t, "app:1.0", reported.Kubernetes.Pods[0].Spec.Containers[0].Image)
+
+	require.Len(t, reported.Kubernetes.Gateways, 1)
+	require.Len(t, reported.Kubernetes.KNative, 1)
+	for _, u := range append(reported.Kubernetes.Gateways, reported.Kubernetes.KNative...) {
+		spec := u["spec"].(map[string]any)
+		assert.NotContains(t, spec, "secretName")
+		assert.Equal(t, []any{"app.example.com"}, spec["dnsNames"])
+	}
+
+	// the objects of the watchers are left as they were
+	assert.Equal(t, map[string]string{"password": "hunter2"}, configMaps[0].Data)
+	assert.Equal(t, "hunter2", pod.Spec.Containers[0].Env[0].Value)
+	assert.Equal(t, "app-tls", cert.Object["spec"].(map[string]any)["secretName"])
+
+	// and the current snapshot, that the ingresses are resolved from, isn't redacted
+	current := a.currentSnapshot.Kubernetes
+	assert.Same(t, configMaps[0], current.ConfigMaps[0])
+	assert.Same(t, pod, current.Pods[0])
+	require.Len(t, current.GatewayAPIGateways, 1)
+	assert.Same(t, cert, current.GatewayAPIGateways[0])
+}
+
+func TestRedactSnapshot_ResolveIngress(t *testing.T) {
+	rules, err := parseSnapshotRedact([]string{"hash:HTTPRoute.spec.hostnames"})
+	require.NoError(t, err)
+	ctx := dlog.NewTestContext(t, false)
+	snapshot := newGatewayAPISnapshot([]any{"quote.example.com"})
+
+	redacted := redactSnapshot(ctx, snapshot, rules)
+	data, err := json.Marshal(redacted)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "quote.example.com")
+
+	// the hostnames of the routes are still found in the snapshot
+	a := &Agent{clusterDomain: "cluster.local", currentSnapshot: snapshot}
+	info, msg := a.getSnapshotIngress(&rpc.IngressInfoRequest{ServiceId: "quote-uid"})
+	require.NotNil(t, info, msg)
+	assert.Equal(t, "quote.example.com", info.L5Host)
+}
@@ -0,0 +1,161 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// resourcePatchType indicates how a resourcePatchCommand changes its object.
+type resourcePatchType string
+
+const (
+	// resourcePatchTypeApply represents a server-side apply.
+	resourcePatchTypeApply = resourcePatchType("APPLY")
+	// resourcePatchTypeMerge represents a JSON merge patch.
+	resourcePatchTypeMerge = resourcePatchType("MERGE")
+)
+
+// defaultFieldManager is the field manager of server-side applies that don't name one.
+const defaultFieldManager = "ambassador-agent"
+
+// resourceClientFactory is a factory for creating a dynamic Kubernetes client.
+type resourceClientFactory func() (dynamic.Interface, error)
+
+// resourcePatchRule is an entry of the resource patch allow-list.
+type resourcePatchRule struct {
+	resource  schema.GroupResource
+	namespace string // empty or "*" for any namespace
+}
+
+// parseResourcePatchAllowList parses allow-list entries of the form
+// resource[.group][/namespace], e.g. "mappings.getambassador.io/ambassador" or
+// "configmaps". An entry without a namespace, or with the namespace "*", allows
+// the resource in any namespace as well as cluster-scoped resources.
+func parseResourcePatchAllowList(entries []string) ([]resourcePatchRule, error) {
+	rules := make([]resourcePatchRule, 0, len(entries))
+	for _, entry := range entries {
+		resource, namespace, _ := strings.Cut(entry, "/")
+		if resource == "" || strings.Contains(namespace, "/") {
+			return nil, fmt.Errorf("invalid resource patch allow-list entry %q, must be resource[.group][/namespace]", entry)
+		}
+		rules = append(rules, resourcePatchRule{
+			resource:  schema.ParseGroupResource(resource),
+			namespace: namespace,
+		})
+	}
+	return rules, nil
+}
+
+// resourcePatchAllowed tells whether the allow-list permits patching the given
+// resource in the given namespace. An empty allow-list permits nothing.
+func resourcePatchAllowed(rules []resourcePatchRule, gvr schema.GroupVersionResource, namespace string) bool {
+	for _, rule := range rules {
+		if rule.resource != gvr.GroupResource() {
+			continue
+		}
+		if rule.namespace == "" || rule.namespace == "*" || rule.namespace == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// resourcePatchCommand holds a reference to a patch of an arbitrary resource.
+type resourcePatchCommand struct {
+	gvr          schema.GroupVersionResource
+	namespace    string
+	name         string
+	patchType    resourcePatchType
+	patch        []byte
+	fieldManager string
+	force        bool
+	dryRun       bool
+
+	resourceVersion string
+}
+
+func (r *resourcePatchCommand) String() string {
+	return fmt.Sprintf("<resource=%s name=%s namespace=%s patch=%s>", r.gvr.GroupResource(), r.name, r.namespace, r.patchType)
+}
+
+// DryRun tells whether the command is only validated by the API server, without changing anything.
+func (r *resourcePatchCommand) DryRun() bool {
+	return r.dryRun
+}
+
+// ResourceVersion returns the resourceVersion of the patched object, once the command has run.
+func (r *resourcePatchCommand) ResourceVersion() string {
+	return r.resourceVersion
+}
+
+// Kind returns the kind of the patched object as given in the patch, which
+// apply patches must have, or an empty string.
+func (r *resourcePatchCommand) Kind() string {
+	var typeMeta struct {
+		Kind string `json:"kind"`
+	}
+	_ = json.Unmarshal(r.patch, &typeMeta)
+	return typeMeta.Kind
+}
+
+// RunWithClientFactory runs the patch using resourceClientFactory to get a dynamic client.
+func (r *resourcePatchCommand) RunWithClientFactory(ctx context.Context, resourceClientFactory resourceClientFactory) error {
+	if !json.Valid(r.patch) {
+		return errors.New("the patch is not a valid JSON document")
+	}
+	client, err := resourceClientFactory()
+	if err != nil {
+		return err
+	}
+	return r.patchResource(ctx, client)
+}
+
+func (r *resourcePatchCommand) patchResource(ctx context.Context, client dynamic.Interface) error {
+	var (
+		pt   types.PatchType
+		opts = metav1.PatchOptions{DryRun: dryRunOption(r.dryRun)}
+	)
+	switch r.patchType {
+	case resourcePatchTypeApply:
+		pt = types.ApplyPatchType
+		opts.FieldManager = r.fieldManager
+		if opts.FieldManager == "" {
+			opts.FieldManager = defaultFieldManager
+		}
+		opts.Force = &r.force
+	case resourcePatchTypeMerge:
+		pt = types.MergePatchType
+		opts.FieldManager = r.fieldManager
+	default:
+		return fmt.Errorf("patch type %s is not supported by the resource patch directive", r.patchType)
+	}
+
+	var ri dynamic.ResourceInterface = client.Resource(r.gvr)
+	if r.namespace != "" {
+		ri = client.Resource(r.gvr).Namespace(r.namespace)
+	}
+	obj, err := ri.Patch(ctx, r.name, pt, r.patch, opts)
+	if err != nil {
+		return fmt.Errorf("failed to patch %s %s: %w", r.gvr.GroupResource(), r.name, err)
+	}
+	r.resourceVersion = obj.GetResourceVersion()
+	return nil
+}
+
+// NewDynamicInterface instantiates a dynamic client to interact with arbitrary Kubernetes resources.
+func NewDynamicInterface() (dynamic.Interface, error) {
+	kubeConfig, err := newK8sRestClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return dynamic.NewForConfig(kubeConfig)
+}
@@ -0,0 +1,197 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/datawire/ambassador-agent/pkg/api/agent"
+	"github.com/datawire/dlib/dlog"
+)
+
+var mappingsGVR = schema.GroupVersionResource{Group: "getambassador.io", Version: "v3alpha1", Resource: "mappings"}
+
+func newFakeDynamicClient(objs ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{mappingsGVR: "MappingList"},
+		objs...,
+	)
+}
+
+func newMapping(namespace, name, prefix string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "getambassador.io/v3alpha1",
+		"kind":       "Mapping",
+		"spec":       map[string]interface{}{"prefix": prefix},
+	}}
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetResourceVersion("41")
+	return obj
+}
+
+func TestParseResourcePatchAllowList(t *testing.T) {
+	rules, err := parseResourcePatchAllowList([]string{"mappings.getambassador.io/ambassador", "configmaps", "hosts.getambassador.io/*"})
+	require.NoError(t, err)
+
+	type testcase struct {
+		gvr       schema.GroupVersionResource
+		namespace string
+		allowed   bool
+	}
+	cases := map[string]testcase{
+		"namespace allowed":        {mappingsGVR, "ambassador", true},
+		"other namespace":          {mappingsGVR, "default", false},
+		"core group":               {schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}, "default", true},
+		"wildcard namespace":       {schema.GroupVersionResource{Group: "getambassador.io", Version: "v2", Resource: "hosts"}, "default", true},
+		"resource not listed":      {schema.GroupVersionResource{Version: "v1", Resource: "secrets"}, "ambassador", false},
+		"same resource, other api": {schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "mappings"}, "ambassador", false},
+	}
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, c.allowed, resourcePatchAllowed(rules, c.gvr, c.namespace))
+		})
+	}
+
+	assert.False(t, resourcePatchAllowed(nil, mappingsGVR, "ambassador"), "empty allow-list allows nothing")
+
+	_, err = parseResourcePatchAllowList([]string{"/ambassador"})
+	assert.Error(t, err)
+	_, err = parseResourcePatchAllowList([]string{"mappings.getambassador.io/a/b"})
+	assert.Error(t, err)
+}
+
+func TestResourcePatchCommand(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("merge patch", func(t *testing.T) {
+		client := newFakeDynamicClient(newMapping("ambassador", "quote", "/backend/"))
+		cmd := &resourcePatchCommand{
+			gvr:       mappingsGVR,
+			namespace: "ambassador",
+			name:      "quote",
+			patchType: resourcePatchTypeMerge,
+			patch:     []byte(`{"spec":{"prefix":"/quote/"}}`),
+		}
+		err := cmd.RunWithClientFactory(ctx, func() (dynamic.Interface, error) { return client, nil })
+		require.NoError(t, err)
+
+		obj, err := client.Resource(mappingsGVR).Namespace("ambassador").Get(ctx, "quote", metav1.GetOptions{})
+		require.NoError(t, err)
+		prefix, _, _ := unstructured.NestedString(obj.Object, "spec", "prefix")
+		assert.Equal(t, "/quote/", prefix)
+		assert.Equal(t, "41", cmd.ResourceVersion())
+	})
+
+	t.Run("server-side apply", func(t *testing.T) {
+		client := newFakeDynamicClient()
+		var patch k8stesting.PatchActionImpl
+		client.PrependReactor("patch", "mappings", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			patch = action.(k8stesting.PatchActionImpl)
+			obj := newMapping("ambassador", "quote", "/quote/")
+			obj.SetResourceVersion("42")
+			return true, obj, nil
+		})
+		cmd := &resourcePatchCommand{
+			gvr:       mappingsGVR,
+			namespace: "ambassador",
+			name:      "quote",
+			patchType: resourcePatchTypeApply,
+			patch:     []byte(`{"apiVersion":"getambassador.io/v3alpha1","kind":"Mapping","spec":{"prefix":"/quote/"}}`),
+			force:     true,
+		}
+		err := cmd.RunWithClientFactory(ctx, func() (dynamic.Interface, error) { return client, nil })
+		require.NoError(t, err)
+		assert.Equal(t, "42", cmd.ResourceVersion())
+		assert.Equal(t, "ambassador", patch.GetNamespace())
+		assert.Equal(t, "quote", patch.GetName())
+		assert.Equal(t, "application/apply-patch+yaml", string(patch.GetPatchType()))
+	})
+
+	t.Run("invalid patch", func(t *testing.T) {
+		cmd := &resourcePatchCommand{gvr: mappingsGVR, name: "quote", patchType: resourcePatchTypeMerge, patch: []byte(`{`)}
+		err := cmd.RunWithClientFactory(ctx, func() (dynamic.Interface, error) {
+			t.Fatal("no client needed for an invalid patch")
+			return nil, nil
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestHandleResourcePatchDirective(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	rules, err := parseResourcePatchAllowList([]string{"mappings.getambassador.io/ambassador"})
+	require.NoError(t, err)
+	client := &MockClient{}
+	a := newTestAgent(client)
+	a.Env = &Env{ResourcePatchAllowList: rules}
+	dynamicClient := newFakeDynamicClient(newMapping("ambassador", "quote", "/backend/"), newMapping("default", "quote", "/backend/"))
+	dh := &BasicDirectiveHandler{
+		resourceClientFactory: func() (dynamic.Interface, error) { return dynamicClient, nil },
+	}
+
+	command := func(id, namespace string, dryRun bool) *agent.Command {
+		return &agent.Command{ResourcePatchCommand: &agent.ResourcePatchCommand{
+			CommandId: id,
+			Group:     mappingsGVR.Group,
+			Version:   mappingsGVR.Version,
+			Resource:  mappingsGVR.Resource,
+			Namespace: namespace,
+			Name:      "quote",
+			PatchType: agent.ResourcePatchCommand_MERGE,
+			Patch:     []byte(`{"spec":{"prefix":"/quote/"}}`),
+			DryRun:    dryRun,
+		}}
+	}
+	dh.HandleDirective(ctx, a, &agent.Directive{ID: "one", Commands: []*agent.Command{
+		command("allowed", "ambassador", false),
+		command("denied", "default", false),
+		command("dry run", "ambassador", true),
+		{ResourcePatchCommand: &agent.ResourcePatchCommand{
+			CommandId: "missing patch type",
+			Group:     mappingsGVR.Group,
+			Version:   mappingsGVR.Version,
+			Resource:  mappingsGVR.Resource,
+			Namespace: "ambassador",
+			Name:      "quote",
+			Patch:     []byte(`{"spec":{"prefix":"/other/"}}`),
+		}},
+	}})
+
+	results := client.GetResults()
+	require.Len(t, results, 4)
+	assert.Equal(t, "allowed", results[0].CommandId)
+	assert.True(t, results[0].Success)
+	assert.Equal(t, "41", results[0].ResourceVersion)
+	assert.Equal(t, "denied", results[1].CommandId)
+	assert.False(t, results[1].Success)
+	assert.Contains(t, results[1].Message, "not allowed")
+	assert.Equal(t, "dry run", results[2].CommandId)
+	assert.True(t, results[2].Success)
+	assert.True(t, results[2].DryRun)
+	assert.Equal(t, "missing patch type", results[3].CommandId)
+	assert.Equal(t, agent.CommandResult_FAILED, results[3].Status)
+
+	obj, err := dynamicClient.Resource(mappingsGVR).Namespace("default").Get(ctx, "quote", metav1.GetOptions{})
+	require.NoError(t, err)
+	prefix, _, _ := unstructured.NestedString(obj.Object, "spec", "prefix")
+	assert.Equal(t, "/backend/", prefix)
+	obj, err = dynamicClient.Resource(mappingsGVR).Namespace("ambassador").Get(ctx, "quote", metav1.GetOptions{})
+	require.NoError(t, err)
+	prefix, _, _ = unstructured.NestedString(obj.Object, "spec", "prefix")
+	assert.Equal(t, "/quote/", prefix)
+}
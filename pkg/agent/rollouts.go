@@ -2,9 +2,13 @@ package agent
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	argov1alpha1 "github.com/argoproj/argo-rollouts/pkg/client/clientset/versioned/typed/rollouts/v1alpha1"
+	apiv1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -22,6 +26,16 @@ const (
 	rolloutActionResume = rolloutAction("RESUME")
 	// rolloutActionAbort represents the "abort" action on a Rollout.
 	rolloutActionAbort = rolloutAction("ABORT")
+	// rolloutActionPromote represents the "promote" action on a Rollout.
+	rolloutActionPromote = rolloutAction("PROMOTE")
+	// rolloutActionPromoteFull represents the "promote --full" action on a Rollout.
+	rolloutActionPromoteFull = rolloutAction("PROMOTE_FULL")
+	// rolloutActionRetry represents the "retry" action on a Rollout.
+	rolloutActionRetry = rolloutAction("RETRY")
+	// rolloutActionRestart represents the "restart" action on a Rollout.
+	rolloutActionRestart = rolloutAction("RESTART")
+	// rolloutActionSetImage represents the "set image" action on a Rollout.
+	rolloutActionSetImage = rolloutAction("SET_IMAGE")
 )
 
 // rolloutsGetterFactory is a factory for creating RolloutsGetter.
@@ -32,12 +46,24 @@ type rolloutCommand struct {
 	namespace   string
 	rolloutName string
 	action      rolloutAction
+	container   string // SET_IMAGE only
+	image       string // SET_IMAGE only
+	dryRun      bool
 }
 
 func (r *rolloutCommand) String() string {
+	if r.action == rolloutActionSetImage {
+		return fmt.Sprintf("<rollout=%s namespace=%s action=%s container=%s image=%s>",
+			r.rolloutName, r.namespace, r.action, r.container, r.image)
+	}
 	return fmt.Sprintf("<rollout=%s namespace=%s action=%s>", r.rolloutName, r.namespace, r.action)
 }
 
+// DryRun tells whether the command is only validated by the API server, without changing anything.
+func (r *rolloutCommand) DryRun() bool {
+	return r.dryRun
+}
+
 // RunWithClientFactory runs the given Rollout command using rolloutsClientFactory to get a RolloutsGetter.
 func (r *rolloutCommand) RunWithClientFactory(ctx context.Context, rolloutsClientFactory rolloutsGetterFactory) error {
 	client, err := rolloutsClientFactory()
@@ -52,6 +78,11 @@ const (
 	abortPatch   = `{"status":{"abort":true}}`
 	retryPatch   = `{"status":{"abort":false}}`
 	pausePatch   = `{"spec":{"paused":true}}`
+	restartPatch = `{"spec":{"restartAt":%q}}`
+
+	promoteFullPatch                  = `{"status":{"promoteFull":true}}`
+	clearPauseConditionsPatch         = `{"status":{"pauseConditions":null}}`
+	clearPauseConditionsPatchWithStep = `{"status":{"pauseConditions":null,"currentStepIndex":%d}}`
 )
 
 func (r *rolloutCommand) patchRollout(ctx context.Context, client argov1alpha1.RolloutsGetter) error {
@@ -68,6 +99,16 @@ func (r *rolloutCommand) patchRollout(ctx context.Context, client argov1alpha1.R
 		err = r.applyStatusPatch(ctx, client, abortPatch)
 	case rolloutActionPause:
 		err = r.applyPatch(ctx, client, pausePatch)
+	case rolloutActionPromote:
+		err = r.promote(ctx, client, false)
+	case rolloutActionPromoteFull:
+		err = r.promote(ctx, client, true)
+	case rolloutActionRetry:
+		err = r.applyStatusPatch(ctx, client, retryPatch)
+	case rolloutActionRestart:
+		err = r.applyPatch(ctx, client, fmt.Sprintf(restartPatch, time.Now().UTC().Format(time.RFC3339)))
+	case rolloutActionSetImage:
+		err = r.setImage(ctx, client)
 	default:
 		err := fmt.Errorf(
 			"tried to perform unknown action '%s' on rollout %s (%s)",
@@ -92,6 +133,86 @@ func (r *rolloutCommand) patchRollout(ctx context.Context, client argov1alpha1.R
 	return nil
 }
 
+// promote skips the current pause of a Rollout, or all of its remaining steps when full is true.
+// This mirrors the promote command of the Argo Rollouts CLI, as seen at https://github.com/argoproj/argo-rollouts/blob/v1.6.0/pkg/kubectl-argo-rollouts/cmd/promote/promote.go.
+func (r *rolloutCommand) promote(ctx context.Context, client argov1alpha1.RolloutsGetter, full bool) error {
+	rollout, err := client.Rollouts(r.namespace).Get(ctx, r.rolloutName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	var statusPatch string
+	switch {
+	case full:
+		if rollout.Status.CurrentPodHash == rollout.Status.StableRS {
+			// Nothing left to promote
+			return nil
+		}
+		statusPatch = promoteFullPatch
+	case len(rollout.Status.PauseConditions) > 0 || rollout.Spec.Strategy.Canary == nil:
+		statusPatch = clearPauseConditionsPatch
+	default:
+		var index int32
+		if rollout.Status.CurrentStepIndex != nil {
+			index = *rollout.Status.CurrentStepIndex
+		}
+		if int(index) < len(rollout.Spec.Strategy.Canary.Steps) {
+			index++
+		}
+		statusPatch = fmt.Sprintf(clearPauseConditionsPatchWithStep, index)
+	}
+
+	if rollout.Spec.Paused {
+		if err = r.applyPatch(ctx, client, unpausePatch); err != nil {
+			return err
+		}
+	}
+	return r.applyStatusPatch(ctx, client, statusPatch)
+}
+
+// setImage sets the image of the container named r.container, or of all containers when it is "*".
+func (r *rolloutCommand) setImage(ctx context.Context, client argov1alpha1.RolloutsGetter) error {
+	if r.container == "" || r.image == "" {
+		return errors.New("a container and an image are required to set the image of a rollout")
+	}
+	rollout, err := client.Rollouts(r.namespace).Get(ctx, r.rolloutName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	podSpec := rollout.Spec.Template.Spec
+	ops := make([]map[string]interface{}, 0)
+	for _, list := range []struct {
+		field      string
+		containers []apiv1.Container
+	}{
+		{"initContainers", podSpec.InitContainers},
+		{"containers", podSpec.Containers},
+	} {
+		for i, container := range list.containers {
+			if r.container != "*" && container.Name != r.container {
+				continue
+			}
+			path := fmt.Sprintf("/spec/template/spec/%s/%d", list.field, i)
+			// Make sure that the container didn't move since we looked
+			ops = append(ops,
+				map[string]interface{}{"op": "test", "path": path + "/name", "value": container.Name},
+				map[string]interface{}{"op": "replace", "path": path + "/image", "value": r.image},
+			)
+		}
+	}
+	if len(ops) == 0 {
+		return fmt.Errorf("container %s not found in rollout %s (%s)", r.container, r.rolloutName, r.namespace)
+	}
+
+	patch, err := json.Marshal(ops)
+	if err != nil {
+		return err
+	}
+	_, err = client.Rollouts(r.namespace).Patch(ctx, r.rolloutName, types.JSONPatchType, patch, r.patchOptions())
+	return err
+}
+
 func (r *rolloutCommand) applyPatch(ctx context.Context, client argov1alpha1.RolloutsGetter, patch string) error {
 	rollout := client.Rollouts(r.namespace)
 	_, err := rollout.Patch(
@@ -99,7 +220,7 @@ func (r *rolloutCommand) applyPatch(ctx context.Context, client argov1alpha1.Rol
 		r.rolloutName,
 		types.MergePatchType,
 		[]byte(patch),
-		metav1.PatchOptions{},
+		r.patchOptions(),
 	)
 	return err
 }
@@ -114,7 +235,7 @@ func (r *rolloutCommand) applyStatusPatch(ctx context.Context, client argov1alph
 		r.rolloutName,
 		types.MergePatchType,
 		[]byte(patch),
-		metav1.PatchOptions{},
+		r.patchOptions(),
 		"status",
 	)
 	if err != nil && k8serrors.IsNotFound(err) {
@@ -123,12 +244,16 @@ func (r *rolloutCommand) applyStatusPatch(ctx context.Context, client argov1alph
 			r.rolloutName,
 			types.MergePatchType,
 			[]byte(patch),
-			metav1.PatchOptions{},
+			r.patchOptions(),
 		)
 	}
 	return err
 }
 
+func (r *rolloutCommand) patchOptions() metav1.PatchOptions {
+	return metav1.PatchOptions{DryRun: dryRunOption(r.dryRun)}
+}
+
 // NewArgoRolloutsGetter creates a RolloutsGetter from Argo's v1alpha1 API.
 func NewArgoRolloutsGetter() (argov1alpha1.RolloutsGetter, error) {
 	kubeConfig, err := newK8sRestClient()
@@ -2,14 +2,20 @@ package agent
 
 import (
 	context "context"
+	"encoding/json"
 	"testing"
+	"time"
 
 	alpha1 "github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
 	"github.com/argoproj/argo-rollouts/pkg/client/clientset/versioned/typed/rollouts/v1alpha1"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/utils/pointer"
 
 	"github.com/datawire/dlib/dlog"
 )
@@ -27,6 +33,7 @@ func (m *mockRolloutsGetter) Rollouts(namespace string) v1alpha1.RolloutInterfac
 }
 
 type mockRolloutInterface struct {
+	rollout         *alpha1.Rollout
 	latestName      string
 	latestPatchType types.PatchType
 	latestOptions   metav1.PatchOptions
@@ -57,7 +64,10 @@ func (m *mockRolloutInterface) DeleteCollection(ctx context.Context, opts metav1
 }
 
 func (m *mockRolloutInterface) Get(ctx context.Context, name string, opts metav1.GetOptions) (*alpha1.Rollout, error) {
-	panic("implement me")
+	if m.rollout == nil || m.rollout.Name != name {
+		return nil, k8serrors.NewNotFound(alpha1.Resource("rollouts"), name)
+	}
+	return m.rollout, nil
 }
 
 func (m *mockRolloutInterface) List(ctx context.Context, opts metav1.ListOptions) (*alpha1.RolloutList, error) {
@@ -82,13 +92,36 @@ func TestRolloutCommand_RunWithClient(t *testing.T) {
 		namespace   string
 		rolloutName string
 		action      rolloutAction
+		container   string
+		image       string
+	}
+	canary := &alpha1.Rollout{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-rollout", Namespace: "default"},
+		Spec: alpha1.RolloutSpec{
+			Paused: true,
+			Strategy: alpha1.RolloutStrategy{Canary: &alpha1.CanaryStrategy{
+				Steps: []alpha1.CanaryStep{{SetWeight: pointer.Int32(20)}, {Pause: &alpha1.RolloutPause{}}, {SetWeight: pointer.Int32(100)}},
+			}},
+			Template: apiv1.PodTemplateSpec{Spec: apiv1.PodSpec{
+				InitContainers: []apiv1.Container{{Name: "init", Image: "busybox:1"}},
+				Containers:     []apiv1.Container{{Name: "app", Image: "app:1"}, {Name: "sidecar", Image: "sidecar:1"}},
+			}},
+		},
+		Status: alpha1.RolloutStatus{
+			CurrentStepIndex: pointer.Int32(1),
+			CurrentPodHash:   "abc",
+			StableRS:         "def",
+		},
 	}
 	tests := []struct {
 		name             string
 		fields           fields
+		rollout          *alpha1.Rollout
+		wantPatchType    types.PatchType
 		wantPatches      []string
 		wantSubresources []string
 		wantErr          assert.ErrorAssertionFunc
+		wantErrMsg       string
 	}{
 		{
 			name: "Pausing a rollout",
@@ -122,11 +155,102 @@ func TestRolloutCommand_RunWithClient(t *testing.T) {
 			wantSubresources: []string{"status"},
 			wantErr:          nil,
 		},
+		{
+			name: "Retry a rollout",
+			fields: fields{
+				namespace:   "default",
+				rolloutName: "my-rollout",
+				action:      rolloutActionRetry,
+			},
+			wantPatches:      []string{`{"status":{"abort":false}}`},
+			wantSubresources: []string{"status"},
+		},
+		{
+			name: "Promote a canary to its next step",
+			fields: fields{
+				namespace:   "default",
+				rolloutName: "my-rollout",
+				action:      rolloutActionPromote,
+			},
+			rollout:          canary,
+			wantPatches:      []string{`{"spec":{"paused":false}}`, `{"status":{"pauseConditions":null,"currentStepIndex":2}}`},
+			wantSubresources: []string{"status"},
+		},
+		{
+			name: "Fully promote a rollout",
+			fields: fields{
+				namespace:   "default",
+				rolloutName: "my-rollout",
+				action:      rolloutActionPromoteFull,
+			},
+			rollout:          canary,
+			wantPatches:      []string{`{"spec":{"paused":false}}`, `{"status":{"promoteFull":true}}`},
+			wantSubresources: []string{"status"},
+		},
+		{
+			name: "Set the image of a container",
+			fields: fields{
+				namespace:   "default",
+				rolloutName: "my-rollout",
+				action:      rolloutActionSetImage,
+				container:   "sidecar",
+				image:       "sidecar:2",
+			},
+			rollout:       canary,
+			wantPatchType: types.JSONPatchType,
+			wantPatches: []string{
+				`[{"op":"test","path":"/spec/template/spec/containers/1/name","value":"sidecar"},` +
+					`{"op":"replace","path":"/spec/template/spec/containers/1/image","value":"sidecar:2"}]`,
+			},
+		},
+		{
+			name: "Set the image of all containers",
+			fields: fields{
+				namespace:   "default",
+				rolloutName: "my-rollout",
+				action:      rolloutActionSetImage,
+				container:   "*",
+				image:       "app:2",
+			},
+			rollout:       canary,
+			wantPatchType: types.JSONPatchType,
+			wantPatches: []string{
+				`[{"op":"test","path":"/spec/template/spec/initContainers/0/name","value":"init"},` +
+					`{"op":"replace","path":"/spec/template/spec/initContainers/0/image","value":"app:2"},` +
+					`{"op":"test","path":"/spec/template/spec/containers/0/name","value":"app"},` +
+					`{"op":"replace","path":"/spec/template/spec/containers/0/image","value":"app:2"},` +
+					`{"op":"test","path":"/spec/template/spec/containers/1/name","value":"sidecar"},` +
+					`{"op":"replace","path":"/spec/template/spec/containers/1/image","value":"app:2"}]`,
+			},
+		},
+		{
+			name: "Set the image of an unknown container",
+			fields: fields{
+				namespace:   "default",
+				rolloutName: "my-rollout",
+				action:      rolloutActionSetImage,
+				container:   "other",
+				image:       "other:2",
+			},
+			rollout:    canary,
+			wantErrMsg: "container other not found in rollout my-rollout (default)",
+		},
+		{
+			name: "Set the image without an image",
+			fields: fields{
+				namespace:   "default",
+				rolloutName: "my-rollout",
+				action:      rolloutActionSetImage,
+				container:   "app",
+			},
+			rollout:    canary,
+			wantErrMsg: "a container and an image are required to set the image of a rollout",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockRolloutInterface := &mockRolloutInterface{}
+			mockRolloutInterface := &mockRolloutInterface{rollout: tt.rollout}
 			mockRolloutsGetter := &mockRolloutsGetter{mockRolloutInterface: mockRolloutInterface}
 
 			mockRolloutsFactory := rolloutsGetterFactory(func() (v1alpha1.RolloutsGetter, error) {
@@ -137,17 +261,62 @@ func TestRolloutCommand_RunWithClient(t *testing.T) {
 				namespace:   tt.fields.namespace,
 				rolloutName: tt.fields.rolloutName,
 				action:      tt.fields.action,
+				container:   tt.fields.container,
+				image:       tt.fields.image,
 			}
-			ctx := dlog.NewTestContext(t, true)
+			// failing commands log errors
+			ctx := dlog.NewTestContext(t, tt.wantErrMsg == "")
 			err := r.RunWithClientFactory(ctx, mockRolloutsFactory)
 
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				assert.Empty(t, mockRolloutInterface.patches)
+				return
+			}
 			assert.NoError(t, err)
+			wantPatchType := tt.wantPatchType
+			if wantPatchType == "" {
+				wantPatchType = types.MergePatchType
+			}
 			assert.Equal(t, tt.fields.namespace, mockRolloutsGetter.latestNamespace)
 			assert.Equal(t, tt.fields.rolloutName, mockRolloutInterface.latestName)
-			assert.Equal(t, types.MergePatchType, mockRolloutInterface.latestPatchType)
+			assert.Equal(t, wantPatchType, mockRolloutInterface.latestPatchType)
 			assert.Equal(t, tt.wantPatches, mockRolloutInterface.patches)
 			assert.Equal(t, tt.wantSubresources, mockRolloutInterface.subresources)
 			assert.Equal(t, metav1.PatchOptions{}, mockRolloutInterface.latestOptions)
 		})
 	}
 }
+
+func TestRolloutCommand_Restart(t *testing.T) {
+	mockRolloutInterface := &mockRolloutInterface{}
+	mockRolloutsGetter := &mockRolloutsGetter{mockRolloutInterface: mockRolloutInterface}
+	r := &rolloutCommand{namespace: "default", rolloutName: "my-rollout", action: rolloutActionRestart}
+
+	ctx := dlog.NewTestContext(t, true)
+	err := r.RunWithClientFactory(ctx, func() (v1alpha1.RolloutsGetter, error) { return mockRolloutsGetter, nil })
+	require.NoError(t, err)
+
+	require.Len(t, mockRolloutInterface.patches, 1)
+	var patch alpha1.Rollout
+	require.NoError(t, json.Unmarshal([]byte(mockRolloutInterface.patches[0]), &patch))
+	require.NotNil(t, patch.Spec.RestartAt)
+	assert.WithinDuration(t, time.Now(), patch.Spec.RestartAt.Time, time.Minute)
+	assert.Empty(t, mockRolloutInterface.subresources)
+}
+
+func TestRolloutCommand_DryRun(t *testing.T) {
+	mockRolloutInterface := &mockRolloutInterface{}
+	mockRolloutsGetter := &mockRolloutsGetter{mockRolloutInterface: mockRolloutInterface}
+	r := &rolloutCommand{namespace: "default", rolloutName: "my-rollout", action: rolloutActionAbort, dryRun: true}
+
+	ctx := dlog.NewTestContext(t, true)
+	err := r.RunWithClientFactory(ctx, func() (v1alpha1.RolloutsGetter, error) { return mockRolloutsGetter, nil })
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{metav1.DryRunAll}, mockRolloutInterface.latestOptions.DryRun)
+	result := newCommandResult("abort", r, nil)
+	assert.True(t, result.Success)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, "dry run, <rollout=my-rollout namespace=default action=ABORT> would succeed", result.Message)
+}
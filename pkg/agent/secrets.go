@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	apiv1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -16,8 +17,16 @@ import (
 type secretSyncAction string
 
 const (
-	secretSyncActionSet    = secretSyncAction("SET")
-	secretSyncActionDelete = secretSyncAction("DELETE")
+	secretSyncActionSet     = secretSyncAction("SET")
+	secretSyncActionDelete  = secretSyncAction("DELETE")
+	secretSyncActionReplace = secretSyncAction("REPLACE")
+)
+
+const (
+	// managedByLabel marks the secrets created by the agent, which are the only
+	// ones it changes unless told otherwise.
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedByAgent = "ambassador-agent"
 )
 
 // SecretInterface describes the operations used to manage secrets in Kubernetes.
@@ -26,6 +35,7 @@ type SecretInterface interface {
 	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
 	Get(ctx context.Context, name string, opts metav1.GetOptions) (*apiv1.Secret, error)
 	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (result *apiv1.Secret, err error)
+	Update(ctx context.Context, secret *apiv1.Secret, opts metav1.UpdateOptions) (*apiv1.Secret, error)
 }
 
 // secretsGetterFactory is a factory for creating SecretsGetter.
@@ -36,12 +46,36 @@ type secretSyncCommand struct {
 	namespace string
 	action    secretSyncAction
 	secret    map[string][]byte
+	dryRun    bool
+
+	secretType  apiv1.SecretType // Opaque if empty
+	labels      map[string]string
+	annotations map[string]string
+
+	// allowUnowned allows changing secrets that weren't created by the agent.
+	allowUnowned bool
+	// adopt allows changing the secret when it has no managed-by label, e.g. because an
+	// agent that didn't label its secrets created it, and labels it as created by the agent.
+	adopt bool
+
+	// outcome says what happened to the secret, once the command has run.
+	outcome string
 }
 
 func (s *secretSyncCommand) String() string {
 	return fmt.Sprintf("<secret=%s namespace=%s action=%s>", s.name, s.namespace, s.action)
 }
 
+// DryRun tells whether the command is only validated by the API server, without changing anything.
+func (s *secretSyncCommand) DryRun() bool {
+	return s.dryRun
+}
+
+// DryRunMessage describes what the command would have done to the secret.
+func (s *secretSyncCommand) DryRunMessage() string {
+	return fmt.Sprintf("dry run, secret %s (%s) would be %s", s.name, s.namespace, s.outcome)
+}
+
 func (s *secretSyncCommand) RunWithClientFactory(
 	ctx context.Context, secretGetterFactory secretsGetterFactory,
 ) error {
@@ -53,38 +87,125 @@ func (s *secretSyncCommand) RunWithClientFactory(
 	return s.syncSecret(ctx, client)
 }
 
-func (s *secretSyncCommand) getOps(insertRoot bool) ([]map[string]interface{}, error) {
+// getOps returns the JSON patch operations that add the values to the field at
+// path, e.g. /data, or that remove their keys from it. insertRoot adds the
+// field first, which is required when the object doesn't have it.
+func getOps(path string, values map[string]string, remove, insertRoot bool) []map[string]interface{} {
 	ops := make([]map[string]interface{}, 0)
-	// if the secret is empty, this is required.
+	// if the object has no such field, this is required.
 	if insertRoot {
 		ops = append(ops, map[string]interface{}{
 			"op":    "add",
-			"path":  "/data",
+			"path":  path,
 			"value": map[string]interface{}{},
 		})
 	}
-	switch s.action {
-	case secretSyncActionDelete:
-		for key := range s.secret {
+	for key, value := range values {
+		if remove {
 			ops = append(ops, map[string]interface{}{
 				"op":   "remove",
-				"path": fmt.Sprintf("/data/%s", key),
+				"path": path + "/" + escapeJSONPointer(key),
 			})
-		}
-	case secretSyncActionSet:
-		for key, value := range s.secret {
+		} else {
 			ops = append(ops, map[string]interface{}{
 				"op":    "add",
-				"path":  fmt.Sprintf("/data/%s", key),
-				"value": base64.StdEncoding.EncodeToString(value),
+				"path":  path + "/" + escapeJSONPointer(key),
+				"value": value,
 			})
 		}
-	default:
-		return nil, fmt.Errorf(
-			"action %s is not supported by the secret sync directive", s.action,
-		)
 	}
-	return ops, nil
+	return ops
+}
+
+// encodeValues returns the values base64 encoded, as they are in the JSON of secrets and
+// the binaryData of ConfigMaps.
+func encodeValues(values map[string][]byte) map[string]string {
+	encoded := make(map[string]string, len(values))
+	for key, value := range values {
+		encoded[key] = base64.StdEncoding.EncodeToString(value)
+	}
+	return encoded
+}
+
+// metadataOps returns the operations that add the labels and annotations to an
+// object with the given metadata, and the label of the agent when it adopts the object.
+func metadataOps(meta *metav1.ObjectMeta, labels, annotations map[string]string, adopt bool) []map[string]interface{} {
+	labels = userLabels(labels)
+	if adopt {
+		labels[managedByLabel] = managedByAgent
+	}
+	var ops []map[string]interface{}
+	for _, field := range []struct {
+		path     string
+		existing map[string]string
+		values   map[string]string
+	}{
+		{"/metadata/labels", meta.Labels, labels},
+		{"/metadata/annotations", meta.Annotations, annotations},
+	} {
+		if len(field.values) == 0 {
+			continue
+		}
+		ops = append(ops, getOps(field.path, field.values, false, field.existing == nil)...)
+	}
+	return ops
+}
+
+// mergeMetadata adds the labels and annotations to the given metadata, and the
+// label of the agent when it adopts the object.
+func mergeMetadata(meta *metav1.ObjectMeta, labels, annotations map[string]string, adopt bool) {
+	labels = userLabels(labels)
+	if adopt {
+		labels[managedByLabel] = managedByAgent
+	}
+	for key, value := range labels {
+		if meta.Labels == nil {
+			meta.Labels = make(map[string]string)
+		}
+		meta.Labels[key] = value
+	}
+	for key, value := range annotations {
+		if meta.Annotations == nil {
+			meta.Annotations = make(map[string]string)
+		}
+		meta.Annotations[key] = value
+	}
+}
+
+// userLabels returns the labels given by a command, except the one that tells
+// whether the agent owns the object, which only the agent sets.
+func userLabels(labels map[string]string) map[string]string {
+	filtered := make(map[string]string, len(labels))
+	for key, value := range labels {
+		if key != managedByLabel {
+			filtered[key] = value
+		}
+	}
+	return filtered
+}
+
+// ownedLabels returns the labels of an object created by the agent.
+func ownedLabels(labels map[string]string) map[string]string {
+	owned := userLabels(labels)
+	owned[managedByLabel] = managedByAgent
+	return owned
+}
+
+// ownedByAgent tells whether the agent created the object.
+func ownedByAgent(meta *metav1.ObjectMeta) bool {
+	return meta.Labels[managedByLabel] == managedByAgent
+}
+
+// adoptable tells whether the object can be adopted by the agent, i.e. whether it
+// isn't managed by anything.
+func adoptable(meta *metav1.ObjectMeta) bool {
+	_, managed := meta.Labels[managedByLabel]
+	return !managed
+}
+
+// escapeJSONPointer escapes a key for use in a JSON patch path, e.g. app.kubernetes.io/name.
+func escapeJSONPointer(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
 }
 
 func (s *secretSyncCommand) syncSecret(ctx context.Context, client SecretInterface) error {
@@ -97,29 +218,60 @@ func (s *secretSyncCommand) syncSecret(ctx context.Context, client SecretInterfa
 
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
-			if s.action == secretSyncActionSet {
+			if s.action == secretSyncActionSet || s.action == secretSyncActionReplace {
+				secretType := s.secretType
+				if secretType == "" {
+					secretType = apiv1.SecretTypeOpaque
+				}
 				_, err = client.Create(ctx, &apiv1.Secret{
 					TypeMeta: metav1.TypeMeta{},
 					ObjectMeta: metav1.ObjectMeta{
-						Name:      s.name,
-						Namespace: s.namespace,
+						Name:        s.name,
+						Namespace:   s.namespace,
+						Labels:      ownedLabels(s.labels),
+						Annotations: s.annotations,
 					},
 					Data: s.secret,
-					Type: apiv1.SecretTypeOpaque,
-				}, metav1.CreateOptions{})
+					Type: secretType,
+				}, metav1.CreateOptions{DryRun: dryRunOption(s.dryRun)})
 
 				if err != nil {
 					return fmt.Errorf("failed to create the secret: %w", err)
 				}
+				s.outcome = "created"
+			} else {
+				s.outcome = "left alone, it does not exist"
 			}
 			return nil
 		}
 		return fmt.Errorf("failed to get the secret %s: %w", s.name, err)
 	}
 
-	ops, err := s.getOps(len(secret.Data) == 0)
-	if err != nil {
-		return fmt.Errorf("failed to generate required update operations: %w", err)
+	adopt := s.adopt && adoptable(&secret.ObjectMeta)
+	if !s.allowUnowned && !adopt && !ownedByAgent(&secret.ObjectMeta) {
+		return fmt.Errorf("secret %s (%s) was not created by the agent, refusing to change it", s.name, s.namespace)
+	}
+
+	if s.secretType != "" && s.action != secretSyncActionDelete && s.secretType != secret.Type {
+		return fmt.Errorf("secret %s is of type %s, which can't be changed to %s", s.name, secret.Type, s.secretType)
+	}
+
+	if s.action == secretSyncActionReplace {
+		return s.replaceSecret(ctx, client, secret, adopt)
+	}
+
+	var ops []map[string]interface{}
+	switch s.action {
+	case secretSyncActionSet:
+		ops = getOps("/data", encodeValues(s.secret), false, len(secret.Data) == 0)
+		ops = append(ops, metadataOps(&secret.ObjectMeta, s.labels, s.annotations, adopt)...)
+	case secretSyncActionDelete:
+		ops = getOps("/data", encodeValues(s.secret), true, len(secret.Data) == 0)
+		ops = append(ops, metadataOps(&secret.ObjectMeta, nil, nil, adopt)...)
+	default:
+		return fmt.Errorf(
+			"failed to generate required update operations: action %s is not supported by the secret sync directive", s.action,
+		)
 	}
 
 	opsJSON, err := json.Marshal(ops)
@@ -127,23 +279,64 @@ func (s *secretSyncCommand) syncSecret(ctx context.Context, client SecretInterfa
 		return fmt.Errorf("failed to generate patch ops: %w", err)
 	}
 
-	secret, err = client.Patch(ctx, s.name, types.JSONPatchType, opsJSON, metav1.PatchOptions{})
+	secret, err = client.Patch(ctx, s.name, types.JSONPatchType, opsJSON, metav1.PatchOptions{DryRun: dryRunOption(s.dryRun)})
 
 	if err != nil {
 		return fmt.Errorf("failed to update the secret: %w", err)
 	}
+	s.outcome = "updated"
 
 	// if no keys left, we should delete the secret.
 	if len(secret.Data) == 0 {
-		err := client.Delete(ctx, s.name, metav1.DeleteOptions{})
+		err := client.Delete(ctx, s.name, metav1.DeleteOptions{DryRun: dryRunOption(s.dryRun)})
 		if err != nil {
 			return fmt.Errorf("failed to clean up the secret %s: %w", s.name, err)
 		}
+		s.outcome = "deleted"
 	}
 
 	return nil
 }
 
+// replaceSecret makes the keys of the secret exactly those of the command. It
+// fails rather than overwrite the changes made since the secret was read.
+func (s *secretSyncCommand) replaceSecret(ctx context.Context, client SecretInterface, secret *apiv1.Secret, adopt bool) error {
+	secret = secret.DeepCopy()
+	secret.Data = s.secret
+	secret.StringData = nil
+	mergeMetadata(&secret.ObjectMeta, s.labels, s.annotations, adopt)
+
+	// The update is rejected with a conflict when the resourceVersion read above is no longer current
+	secret, err := client.Update(ctx, secret, metav1.UpdateOptions{DryRun: dryRunOption(s.dryRun)})
+	if err != nil {
+		return fmt.Errorf("failed to replace the secret %s: %w", s.name, err)
+	}
+	s.outcome = "replaced"
+
+	// if no keys left, we should delete the secret, as SET and DELETE do.
+	if len(secret.Data) == 0 {
+		err := client.Delete(ctx, s.name, metav1.DeleteOptions{DryRun: dryRunOption(s.dryRun)})
+		if err != nil {
+			return fmt.Errorf("failed to clean up the secret %s: %w", s.name, err)
+		}
+		s.outcome = "deleted"
+	}
+	return nil
+}
+
+// adoptSecret tells whether the secret is listed in AGENT_SECRET_SYNC_ADOPT.
+func (e *Env) adoptSecret(namespace, name string) bool {
+	if e == nil {
+		return false
+	}
+	for _, entry := range e.SecretSyncAdopt {
+		if entry == namespace+"/"+name {
+			return true
+		}
+	}
+	return false
+}
+
 // NewSecretsGetter instantiates a client to interact with the Kubernetes secret API.
 func NewSecretsGetter(namespace string) (SecretInterface, error) {
 	kubeConfig, err := newK8sRestClient()
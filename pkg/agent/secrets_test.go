@@ -3,15 +3,20 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	apiv1 "k8s.io/api/core/v1"
 	errorsv1 "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/datawire/ambassador-agent/pkg/api/agent"
+	"github.com/datawire/dlib/dlog"
 )
 
 func TestRunWithClientFactorySet(t *testing.T) {
@@ -146,6 +151,316 @@ func TestRunWithClientFactoryDelete(t *testing.T) {
 	})
 }
 
+func TestRunWithClientFactoryTypeAndMetadata(t *testing.T) {
+	t.Run("Create typed secret", func(t *testing.T) {
+		// given
+		cmd := wrapNewCommand("tls-cert", secretSyncActionSet, map[string][]byte{
+			"tls.crt": []byte("cert"),
+			"tls.key": []byte("key"),
+		})
+		cmd.secretType = apiv1.SecretTypeTLS
+		cmd.labels = map[string]string{"app.kubernetes.io/name": "quote"}
+		cmd.annotations = map[string]string{"owner": "team-a"}
+		secretGetter := newSecretGetterMock()
+
+		// when
+		err := cmd.RunWithClientFactory(
+			context.Background(), wrapSecretGetterFactoryMock(secretGetter))
+
+		// then
+		assert.NoError(t, err, "no error")
+		secret := secretGetter.findSecret("tls-cert")
+		require.NotNil(t, secret)
+		assert.Equal(t, apiv1.SecretTypeTLS, secret.Type)
+		assert.Equal(t, map[string]string{"app.kubernetes.io/name": "quote", managedByLabel: managedByAgent}, secret.Labels)
+		assert.Equal(t, map[string]string{"owner": "team-a"}, secret.Annotations)
+	})
+	t.Run("Add labels to existing secret", func(t *testing.T) {
+		// given
+		cmd := wrapNewCommand("some-existing-api-key", secretSyncActionSet, nil)
+		cmd.labels = map[string]string{"app.kubernetes.io/name": "quote"}
+		secretGetter := newSecretGetterMock()
+
+		// when
+		err := cmd.RunWithClientFactory(
+			context.Background(), wrapSecretGetterFactoryMock(secretGetter))
+
+		// then
+		assert.NoError(t, err, "no error")
+		secret := secretGetter.findSecret("some-existing-api-key")
+		assert.Equal(t, map[string]string{"app.kubernetes.io/name": "quote", managedByLabel: managedByAgent}, secret.Labels)
+		assert.Nil(t, secret.Annotations, "no annotations added")
+		assert.Len(t, secret.Data, 2, "key added")
+	})
+	t.Run("Type can't change", func(t *testing.T) {
+		// given
+		cmd := wrapNewCommand("some-existing-api-key", secretSyncActionSet, nil)
+		cmd.secretType = apiv1.SecretTypeDockerConfigJson
+		secretGetter := newSecretGetterMock()
+
+		// when
+		err := cmd.RunWithClientFactory(
+			context.Background(), wrapSecretGetterFactoryMock(secretGetter))
+
+		// then
+		assert.EqualError(t, err, "secret some-existing-api-key is of type Opaque, which can't be changed to kubernetes.io/dockerconfigjson")
+	})
+}
+
+func TestRunWithClientFactoryReplace(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// given
+		cmd := wrapNewCommand("some-existing-api-key", secretSyncActionReplace, map[string][]byte{
+			"key-1": []byte("1234"),
+		})
+		cmd.annotations = map[string]string{"owner": "team-a"}
+		secretGetter := newSecretGetterMock()
+
+		// when
+		err := cmd.RunWithClientFactory(
+			context.Background(), wrapSecretGetterFactoryMock(secretGetter))
+
+		// then
+		assert.NoError(t, err, "no error")
+		secret := secretGetter.findSecret("some-existing-api-key")
+		assert.Equal(t, map[string][]byte{"key-1": []byte("1234")}, secret.Data, "keys replaced")
+		assert.Equal(t, map[string]string{"owner": "team-a"}, secret.Annotations)
+		assert.Equal(t, "replaced", cmd.outcome)
+	})
+	t.Run("Empty", func(t *testing.T) {
+		// given
+		cmd := wrapNewCommand("some-existing-api-key", secretSyncActionReplace, map[string][]byte{})
+		secretGetter := newSecretGetterMock()
+
+		// when
+		err := cmd.RunWithClientFactory(
+			context.Background(), wrapSecretGetterFactoryMock(secretGetter))
+
+		// then
+		assert.NoError(t, err, "no error")
+		assert.Nil(t, secretGetter.findSecret("some-existing-api-key"), "secret deleted")
+		assert.Equal(t, "deleted", cmd.outcome)
+	})
+	t.Run("Empty dry run", func(t *testing.T) {
+		// given
+		cmd := wrapNewCommand("some-existing-api-key", secretSyncActionReplace, map[string][]byte{})
+		cmd.dryRun = true
+		secretGetter := newSecretGetterMock()
+
+		// when
+		err := cmd.RunWithClientFactory(
+			context.Background(), wrapSecretGetterFactoryMock(secretGetter))
+
+		// then
+		assert.NoError(t, err, "no error")
+		assert.Equal(t, map[string][]byte{"some-secret": []byte("some-value")},
+			secretGetter.findSecret("some-existing-api-key").Data, "secret left alone")
+		assert.Equal(t, "dry run, secret some-existing-api-key (ambassador) would be deleted", cmd.DryRunMessage())
+	})
+	t.Run("Create", func(t *testing.T) {
+		// given
+		cmd := wrapNewCommand("api-keys-staging", secretSyncActionReplace, nil)
+		secretGetter := newSecretGetterMock()
+
+		// when
+		err := cmd.RunWithClientFactory(
+			context.Background(), wrapSecretGetterFactoryMock(secretGetter))
+
+		// then
+		assert.NoError(t, err, "no error")
+		assert.Equal(t, 4, len(secretGetter.secrets), "secret created")
+	})
+	t.Run("Conflict", func(t *testing.T) {
+		// given
+		cmd := wrapNewCommand("some-existing-api-key", secretSyncActionReplace, nil)
+		secretGetter := &staleSecretGetterMock{newSecretGetterMock()}
+
+		// when
+		err := cmd.RunWithClientFactory(context.Background(), func(namespace string) (SecretInterface, error) {
+			secretGetter.Namespace = namespace
+			return secretGetter, nil
+		})
+
+		// then
+		assert.True(t, errorsv1.IsConflict(errors.Unwrap(err)), "conflict")
+		assert.Equal(t, map[string][]byte{"some-secret": []byte("some-value")},
+			secretGetter.findSecret("some-existing-api-key").Data, "secret left alone")
+	})
+}
+
+func TestRunWithClientFactoryUnowned(t *testing.T) {
+	newUnownedSecretGetterMock := func() *secretGetterMock {
+		secretGetter := newSecretGetterMock()
+		secretGetter.secrets = append(secretGetter.secrets, &apiv1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cert-manager-tls",
+				Namespace: "ambassador",
+				Labels:    map[string]string{managedByLabel: "cert-manager"},
+			},
+			Data: map[string][]byte{"tls.crt": []byte("cert")},
+			Type: apiv1.SecretTypeTLS,
+		})
+		return secretGetter
+	}
+
+	for _, action := range []secretSyncAction{secretSyncActionSet, secretSyncActionDelete, secretSyncActionReplace} {
+		action := action
+		t.Run(string(action)+" refused", func(t *testing.T) {
+			// given
+			cmd := wrapNewCommand("cert-manager-tls", action, map[string][]byte{"tls.crt": nil})
+			secretGetter := newUnownedSecretGetterMock()
+
+			// when
+			err := cmd.RunWithClientFactory(
+				context.Background(), wrapSecretGetterFactoryMock(secretGetter))
+
+			// then
+			assert.EqualError(t, err, "secret cert-manager-tls (ambassador) was not created by the agent, refusing to change it")
+			assert.Equal(t, newUnownedSecretGetterMock().secrets, secretGetter.secrets, "nothing changed")
+		})
+	}
+	t.Run("Allowed by override", func(t *testing.T) {
+		// given
+		cmd := wrapNewCommand("cert-manager-tls", secretSyncActionSet, map[string][]byte{"tls.key": []byte("key")})
+		cmd.labels = map[string]string{managedByLabel: managedByAgent}
+		cmd.allowUnowned = true
+		secretGetter := newUnownedSecretGetterMock()
+
+		// when
+		err := cmd.RunWithClientFactory(
+			context.Background(), wrapSecretGetterFactoryMock(secretGetter))
+
+		// then
+		assert.NoError(t, err, "no error")
+		secret := secretGetter.findSecret("cert-manager-tls")
+		assert.Len(t, secret.Data, 2, "key added")
+		assert.Equal(t, "cert-manager", secret.Labels[managedByLabel], "ownership not taken over")
+	})
+}
+
+func TestRunWithClientFactoryAdopt(t *testing.T) {
+	// a secret created by an agent that didn't label its secrets
+	newLegacySecretGetterMock := func() *secretGetterMock {
+		secretGetter := newSecretGetterMock()
+		secretGetter.secrets = append(secretGetter.secrets, &apiv1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "legacy-api-key", Namespace: "ambassador"},
+			Data:       map[string][]byte{"key-1": []byte("1234")},
+			Type:       apiv1.SecretTypeOpaque,
+		})
+		return secretGetter
+	}
+
+	for _, action := range []secretSyncAction{secretSyncActionSet, secretSyncActionReplace} {
+		action := action
+		t.Run(string(action)+" adopted", func(t *testing.T) {
+			// given
+			cmd := wrapNewCommand("legacy-api-key", action, map[string][]byte{"key-2": []byte("5678")})
+			cmd.adopt = true
+			secretGetter := newLegacySecretGetterMock()
+
+			// when
+			err := cmd.RunWithClientFactory(
+				context.Background(), wrapSecretGetterFactoryMock(secretGetter))
+
+			// then
+			assert.NoError(t, err, "no error")
+			secret := secretGetter.findSecret("legacy-api-key")
+			assert.Contains(t, secret.Data, "key-2", "key added")
+			assert.Equal(t, managedByAgent, secret.Labels[managedByLabel], "secret adopted")
+		})
+	}
+	t.Run("Not adopted without the allow-list", func(t *testing.T) {
+		// given
+		cmd := wrapNewCommand("legacy-api-key", secretSyncActionSet, map[string][]byte{"key-2": []byte("5678")})
+		secretGetter := newLegacySecretGetterMock()
+
+		// when
+		err := cmd.RunWithClientFactory(
+			context.Background(), wrapSecretGetterFactoryMock(secretGetter))
+
+		// then
+		assert.EqualError(t, err, "secret legacy-api-key (ambassador) was not created by the agent, refusing to change it")
+	})
+	t.Run("Managed by something else", func(t *testing.T) {
+		// given
+		cmd := wrapNewCommand("legacy-api-key", secretSyncActionSet, map[string][]byte{"key-2": []byte("5678")})
+		cmd.adopt = true
+		secretGetter := newLegacySecretGetterMock()
+		secretGetter.secrets[len(secretGetter.secrets)-1].Labels = map[string]string{managedByLabel: "cert-manager"}
+
+		// when
+		err := cmd.RunWithClientFactory(
+			context.Background(), wrapSecretGetterFactoryMock(secretGetter))
+
+		// then
+		assert.EqualError(t, err, "secret legacy-api-key (ambassador) was not created by the agent, refusing to change it")
+	})
+	t.Run("Allow-list", func(t *testing.T) {
+		env, err := LoadEnv(func(key string) (string, bool) {
+			if key == "AGENT_SECRET_SYNC_ADOPT" {
+				return "ambassador/legacy-api-key default/other", true
+			}
+			return "", false
+		})
+		require.NoError(t, err)
+		assert.True(t, env.adoptSecret("ambassador", "legacy-api-key"))
+		assert.False(t, env.adoptSecret("default", "legacy-api-key"))
+		assert.False(t, (*Env)(nil).adoptSecret("ambassador", "legacy-api-key"))
+	})
+}
+
+// staleSecretGetterMock returns secrets that were changed by someone else right after being read.
+type staleSecretGetterMock struct {
+	*secretGetterMock
+}
+
+func (s *staleSecretGetterMock) Get(ctx context.Context, name string, opts metav1.GetOptions) (*apiv1.Secret, error) {
+	secret, err := s.secretGetterMock.Get(ctx, name, opts)
+	if err != nil {
+		return nil, err
+	}
+	stale := secret.DeepCopy()
+	secret.ResourceVersion += "1"
+	return stale, nil
+}
+
+func TestRunWithClientFactoryDryRun(t *testing.T) {
+	for name, tc := range map[string]struct {
+		cmd     *secretSyncCommand
+		outcome string
+	}{
+		"Create": {
+			cmd:     wrapNewCommand("api-keys-staging", secretSyncActionSet, nil),
+			outcome: "created",
+		},
+		"Update": {
+			cmd:     wrapNewCommand("some-existing-api-key", secretSyncActionSet, nil),
+			outcome: "updated",
+		},
+		"Delete": {
+			cmd:     wrapNewCommand("some-existing-api-key", secretSyncActionDelete, map[string][]byte{"some-secret": nil}),
+			outcome: "deleted",
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			// given
+			secretGetter := newSecretGetterMock()
+			before := newSecretGetterMock().secrets
+			tc.cmd.dryRun = true
+
+			// when
+			err := tc.cmd.RunWithClientFactory(
+				context.Background(), wrapSecretGetterFactoryMock(secretGetter))
+
+			// then
+			assert.NoError(t, err, "no error")
+			assert.Equal(t, before, secretGetter.secrets, "nothing changed")
+			assert.Equal(t, fmt.Sprintf("dry run, secret %s (ambassador) would be %s", tc.cmd.name, tc.outcome), tc.cmd.DryRunMessage())
+		})
+	}
+}
+
 func wrapNewCommand(name string, action secretSyncAction, secret map[string][]byte) *secretSyncCommand {
 	if secret == nil {
 		secret = map[string][]byte{
@@ -168,6 +483,7 @@ func newSecretGetterMock() *secretGetterMock {
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "some-existing-api-key",
 					Namespace: "ambassador",
+					Labels:    map[string]string{managedByLabel: managedByAgent},
 				},
 				Data: map[string][]byte{
 					"some-secret": []byte("some-value"),
@@ -179,6 +495,7 @@ func newSecretGetterMock() *secretGetterMock {
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "random-error-patch",
 					Namespace: "ambassador",
+					Labels:    map[string]string{managedByLabel: managedByAgent},
 				},
 				Data: map[string][]byte{
 					"some-secret": []byte("some-value"),
@@ -190,6 +507,7 @@ func newSecretGetterMock() *secretGetterMock {
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "random-error-delete",
 					Namespace: "ambassador",
+					Labels:    map[string]string{managedByLabel: managedByAgent},
 				},
 				Data: map[string][]byte{},
 				Type: apiv1.SecretTypeOpaque,
@@ -250,7 +568,9 @@ func (s *secretGetterMock) Create(ctx context.Context, secret *apiv1.Secret, opt
 			},
 		}
 	}
-	s.secrets = append(s.secrets, secret)
+	if len(opts.DryRun) == 0 {
+		s.secrets = append(s.secrets, secret)
+	}
 	return secret, nil
 }
 
@@ -276,29 +596,67 @@ func (s *secretGetterMock) Patch(
 		}
 	}
 
-	var ops []map[string]string
+	if len(opts.DryRun) > 0 {
+		existingSecret = existingSecret.DeepCopy()
+	}
+
+	var ops []map[string]interface{}
 
 	if err := json.Unmarshal(data, &ops); err != nil {
 		return nil, err
 	}
 
 	for _, op := range ops {
-		if op["path"] == "/data" {
+		path := strings.Split(op["path"].(string), "/")
+		if len(path) == 3 && path[1] == "data" {
+			key := path[2]
+			switch op["op"] {
+			case "add":
+				existingSecret.Data[key] = []byte(op["value"].(string))
+			default:
+				delete(existingSecret.Data, key)
+			}
 			continue
 		}
-		key := strings.Split(op["path"], "/")[2]
-
-		switch op["op"] {
-		case "add":
-			existingSecret.Data[key] = []byte(op["value"])
-		default:
-			delete(existingSecret.Data, key)
+		if len(path) == 4 && path[1] == "metadata" {
+			key := strings.NewReplacer("~1", "/", "~0", "~").Replace(path[3])
+			switch path[2] {
+			case "labels":
+				existingSecret.Labels[key] = op["value"].(string)
+			case "annotations":
+				existingSecret.Annotations[key] = op["value"].(string)
+			}
+			continue
+		}
+		if len(path) == 3 && path[1] == "metadata" {
+			switch path[2] {
+			case "labels":
+				existingSecret.Labels = map[string]string{}
+			case "annotations":
+				existingSecret.Annotations = map[string]string{}
+			}
 		}
 	}
 
 	return existingSecret, nil
 }
 
+func (s *secretGetterMock) Update(ctx context.Context, secret *apiv1.Secret, opts metav1.UpdateOptions) (*apiv1.Secret, error) {
+	for i := range s.secrets {
+		if s.secrets[i].Name != secret.Name {
+			continue
+		}
+		if s.secrets[i].ResourceVersion != secret.ResourceVersion {
+			return nil, errorsv1.NewConflict(apiv1.Resource("secrets"), secret.Name, errors.New("the object has been modified"))
+		}
+		if len(opts.DryRun) == 0 {
+			s.secrets[i] = secret
+		}
+		return secret, nil
+	}
+	return nil, errorsv1.NewNotFound(apiv1.Resource("secrets"), secret.Name)
+}
+
 func (s *secretGetterMock) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
 	if strings.Contains(name, "random-error-delete") {
 		return fmt.Errorf("random error")
@@ -306,7 +664,9 @@ func (s *secretGetterMock) Delete(ctx context.Context, name string, opts metav1.
 
 	for i := range s.secrets {
 		if s.secrets[i].Name == name {
-			s.secrets = append(s.secrets[:i], s.secrets[i+1:]...)
+			if len(opts.DryRun) == 0 {
+				s.secrets = append(s.secrets[:i], s.secrets[i+1:]...)
+			}
 			return nil
 		}
 	}
@@ -316,3 +676,32 @@ func (s *secretGetterMock) Delete(ctx context.Context, name string, opts metav1.
 		},
 	}
 }
+
+func TestHandleSecretSyncDirective_DryRun(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	client := &MockClient{}
+	a := newTestAgent(client)
+	a.Env = &Env{CommandsDryRun: true}
+	secretGetter := newSecretGetterMock()
+	dh := &BasicDirectiveHandler{secretsGetterFactory: wrapSecretGetterFactoryMock(secretGetter)}
+
+	dh.HandleDirective(ctx, a, &agent.Directive{ID: "one", Commands: []*agent.Command{
+		{SecretSyncCommand: &agent.SecretSyncCommand{
+			CommandId: "set",
+			Name:      "api-keys-staging",
+			Namespace: "ambassador",
+			Action:    agent.SecretSyncCommand_SET,
+			Secret:    map[string][]byte{"key": []byte("value")},
+		}},
+	}})
+
+	results := client.GetResults()
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Success)
+	assert.True(t, results[0].DryRun)
+	assert.Equal(t, "dry run, secret api-keys-staging (ambassador) would be created", results[0].Message)
+	assert.Len(t, secretGetter.secrets, 3, "nothing created")
+}
@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/types/known/emptypb"
 	core "k8s.io/api/core/v1"
@@ -24,8 +25,13 @@ import (
 func (a *Agent) Service(ctx context.Context) error {
 	svr := grpc.NewServer()
 	agent.RegisterAgentServer(svr, a)
+	metrics := promhttp.HandlerFor(agentMetrics, promhttp.HandlerOpts{})
 	sc := &dhttp.ServerConfig{
 		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet && r.URL.Path == "/metrics" {
+				metrics.ServeHTTP(w, r)
+				return
+			}
 			svr.ServeHTTP(w, r)
 		}),
 	}
@@ -70,16 +76,20 @@ func (a *Agent) getSnapshotIngress(request *agent.IngressInfoRequest) (*agent.In
 	if ksn == nil {
 		return nil, "No Kubernetes snapshot in current snapshot"
 	}
-	svc := findServiceInSnapshot(ksn, types.UID(request.ServiceId))
+	svc := findServiceInSnapshot(ksn.KubernetesSnapshot, types.UID(request.ServiceId))
 	if svc == nil {
 		return nil, fmt.Sprintf("No snapshot found for service %q", request.ServiceId)
 	}
-	mappings := findServiceMappingsInSnapshot(ksn, svc.Name, svc.Namespace)
+	mappings := findServiceMappingsInSnapshot(ksn.KubernetesSnapshot, svc.Name, svc.Namespace)
 	hostName := findHostname(mappings)
 	if hostName == "" {
-		return nil, fmt.Sprintf("Could not resolve hostname in mappings of service %q", request.ServiceId)
+		// The service may be routed with the Gateway API rather than with Mappings
+		if info := findGatewayIngress(ksn, svc, a.clusterDomain); info != nil {
+			return info, ""
+		}
+		return nil, fmt.Sprintf("Could not resolve hostname in mappings or HTTPRoutes of service %q", request.ServiceId)
 	}
-	ingressSvc := findIngressByNameInSnapshot(ksn, "emissary-ingress", "edge-stack", "ambassador")
+	ingressSvc := findIngressByNameInSnapshot(ksn.KubernetesSnapshot, "emissary-ingress", "edge-stack", "ambassador")
 	if ingressSvc == nil {
 		return nil, "No ingress candidate found in cluster"
 	}
@@ -0,0 +1,218 @@
+package agent
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/datawire/ambassador-agent/pkg/api/agent"
+)
+
+// snapshotIndex is the decomposition of a raw snapshot into the objects that
+// can be identified by a UID and the remainder of the snapshot.
+type snapshotIndex struct {
+	snapshotTs *timestamppb.Timestamp
+
+	// objects holds the raw JSON of each object keyed by path and UID.
+	objects map[objectKey][]byte
+
+	// remainder is the raw snapshot with all objects removed.
+	remainder []byte
+}
+
+type objectKey struct {
+	path string
+	uid  string
+}
+
+// objectHash holds a digest of an object, so that the index of an
+// acknowledged snapshot doesn't need to retain the objects themselves.
+type objectHash [sha256.Size]byte
+
+// snapshotDeltaTracker keeps track of the last snapshot that the Director
+// accepted, so that subsequent reports only need to contain what changed.
+type snapshotDeltaTracker struct {
+	mu sync.Mutex
+
+	// resyncPeriod is how often a full snapshot is sent regardless of what
+	// the Director has seen before.
+	resyncPeriod time.Duration
+
+	// unsupported is set when the Director does not implement ReportDeltaStream.
+	unsupported bool
+
+	// baseTs, baseObjects and baseRemainder describe the last accepted report.
+	baseTs        *timestamppb.Timestamp
+	baseObjects   map[objectKey]objectHash
+	baseRemainder objectHash
+	lastFull      time.Time
+}
+
+func newSnapshotDeltaTracker(resyncPeriod time.Duration) *snapshotDeltaTracker {
+	return &snapshotDeltaTracker{resyncPeriod: resyncPeriod}
+}
+
+// RequestFullResync makes the next report a full snapshot.
+func (t *snapshotDeltaTracker) RequestFullResync() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.baseObjects = nil
+}
+
+// Disable makes all subsequent reports full snapshots.
+func (t *snapshotDeltaTracker) Disable() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.unsupported = true
+	t.baseObjects = nil
+}
+
+// Prepare indexes the given report. It returns the delta against the last
+// accepted report, or nil when a full snapshot must be sent instead.
+func (t *snapshotDeltaTracker) Prepare(report *agent.Snapshot) (*snapshotIndex, *agent.SnapshotDelta, error) {
+	idx, err := indexSnapshot(report)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.unsupported || t.resyncPeriod <= 0 || t.baseObjects == nil || time.Since(t.lastFull) >= t.resyncPeriod {
+		return idx, nil, nil
+	}
+
+	delta := &agent.SnapshotDelta{
+		Identity:       report.Identity,
+		BaseSnapshotTs: t.baseTs,
+		ContentType:    report.ContentType,
+		ApiVersion:     report.ApiVersion,
+		SnapshotTs:     report.SnapshotTs,
+	}
+	if sha256.Sum256(idx.remainder) != t.baseRemainder {
+		delta.RawRemainder = idx.remainder
+	}
+	for key, obj := range idx.objects {
+		baseHash, ok := t.baseObjects[key]
+		switch {
+		case !ok:
+			delta.Deltas = append(delta.Deltas, newObjectDelta(agent.ObjectDelta_ADDED, key, obj))
+		case baseHash != sha256.Sum256(obj):
+			delta.Deltas = append(delta.Deltas, newObjectDelta(agent.ObjectDelta_UPDATED, key, obj))
+		}
+	}
+	for key := range t.baseObjects {
+		if _, ok := idx.objects[key]; !ok {
+			delta.Deltas = append(delta.Deltas, newObjectDelta(agent.ObjectDelta_DELETED, key, nil))
+		}
+	}
+	sort.Slice(delta.Deltas, func(i, j int) bool {
+		di, dj := delta.Deltas[i], delta.Deltas[j]
+		if di.Path != dj.Path {
+			return di.Path < dj.Path
+		}
+		return di.Uid < dj.Uid
+	})
+	return idx, delta, nil
+}
+
+// Accept records that the Director has accepted the report with the given
+// index, making it the base for subsequent deltas.
+func (t *snapshotDeltaTracker) Accept(idx *snapshotIndex, full bool) {
+	baseObjects := make(map[objectKey]objectHash, len(idx.objects))
+	for key, obj := range idx.objects {
+		baseObjects[key] = sha256.Sum256(obj)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if full {
+		t.lastFull = time.Now()
+	} else if t.baseObjects == nil {
+		// A full resync was requested while the delta was in flight.
+		return
+	}
+	t.baseTs = idx.snapshotTs
+	t.baseObjects = baseObjects
+	t.baseRemainder = sha256.Sum256(idx.remainder)
+}
+
+func newObjectDelta(deltaType agent.ObjectDelta_Type, key objectKey, obj []byte) *agent.ObjectDelta {
+	return &agent.ObjectDelta{
+		Type:      deltaType,
+		Uid:       key.uid,
+		Path:      key.path,
+		RawObject: obj,
+	}
+}
+
+// indexSnapshot splits the raw JSON snapshot of a report into the objects in
+// its Kubernetes section that carry a metadata.uid, and everything else.
+func indexSnapshot(report *agent.Snapshot) (*snapshotIndex, error) {
+	idx := &snapshotIndex{
+		snapshotTs: report.SnapshotTs,
+		objects:    make(map[objectKey][]byte),
+	}
+
+	var snapshot map[string]json.RawMessage
+	if err := json.Unmarshal(report.RawSnapshot, &snapshot); err != nil {
+		return nil, fmt.Errorf("unable to index snapshot: %w", err)
+	}
+
+	if rawK8s, ok := snapshot["Kubernetes"]; ok && !isJSONNull(rawK8s) {
+		var k8s map[string]json.RawMessage
+		if err := json.Unmarshal(rawK8s, &k8s); err != nil {
+			return nil, fmt.Errorf("unable to index kubernetes snapshot: %w", err)
+		}
+		for section, rawList := range k8s {
+			var list []json.RawMessage
+			if err := json.Unmarshal(rawList, &list); err != nil {
+				// not a list of objects
+				continue
+			}
+			path := "Kubernetes." + section
+			rest := make([]json.RawMessage, 0)
+			for _, obj := range list {
+				var meta struct {
+					Metadata struct {
+						UID string `json:"uid"`
+					} `json:"metadata"`
+				}
+				if err := json.Unmarshal(obj, &meta); err != nil || meta.Metadata.UID == "" {
+					rest = append(rest, obj)
+					continue
+				}
+				idx.objects[objectKey{path: path, uid: meta.Metadata.UID}] = obj
+			}
+			if len(rest) == len(list) {
+				continue
+			}
+			restJSON, err := json.Marshal(rest)
+			if err != nil {
+				return nil, err
+			}
+			k8s[section] = restJSON
+		}
+		k8sJSON, err := json.Marshal(k8s)
+		if err != nil {
+			return nil, err
+		}
+		snapshot["Kubernetes"] = k8sJSON
+	}
+
+	remainder, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	idx.remainder = remainder
+	return idx, nil
+}
+
+func isJSONNull(raw json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
@@ -0,0 +1,242 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/datawire/ambassador-agent/pkg/api/agent"
+	"github.com/datawire/dlib/dlog"
+	"github.com/emissary-ingress/emissary/v3/pkg/kates"
+	snapshotTypes "github.com/emissary-ingress/emissary/v3/pkg/snapshot/v1"
+)
+
+func newDeltaTestPod(uid, name, phase string) *kates.Pod {
+	return &kates.Pod{
+		TypeMeta: metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			UID:       types.UID(uid),
+		},
+		Status: v1.PodStatus{Phase: v1.PodPhase(phase)},
+	}
+}
+
+func newDeltaTestReport(t *testing.T, clusterID string, pods ...*kates.Pod) *agent.Snapshot {
+	raw, err := json.Marshal(&snapshotTypes.Snapshot{
+		AmbassadorMeta: &snapshotTypes.AmbassadorMetaInfo{ClusterID: clusterID},
+		Kubernetes:     &snapshotTypes.KubernetesSnapshot{Pods: pods},
+	})
+	require.NoError(t, err)
+	return &agent.Snapshot{
+		Identity:    &agent.Identity{ClusterId: clusterID},
+		RawSnapshot: raw,
+		ContentType: snapshotTypes.ContentTypeJSON,
+		ApiVersion:  snapshotTypes.ApiVersion,
+		SnapshotTs:  timestamppb.Now(),
+	}
+}
+
+func TestSnapshotDeltaTracker(t *testing.T) {
+	t.Run("first report is full", func(t *testing.T) {
+		tracker := newSnapshotDeltaTracker(time.Hour)
+		report := newDeltaTestReport(t, "cluster", newDeltaTestPod("1", "pod-1", "Running"))
+
+		idx, delta, err := tracker.Prepare(report)
+		require.NoError(t, err)
+		assert.Nil(t, delta)
+		assert.Len(t, idx.objects, 1)
+	})
+	t.Run("unchanged report yields empty delta", func(t *testing.T) {
+		tracker := newSnapshotDeltaTracker(time.Hour)
+		report := newDeltaTestReport(t, "cluster", newDeltaTestPod("1", "pod-1", "Running"))
+		idx, _, err := tracker.Prepare(report)
+		require.NoError(t, err)
+		tracker.Accept(idx, true)
+
+		_, delta, err := tracker.Prepare(newDeltaTestReport(t, "cluster", newDeltaTestPod("1", "pod-1", "Running")))
+		require.NoError(t, err)
+		require.NotNil(t, delta)
+		assert.Empty(t, delta.Deltas)
+		assert.Empty(t, delta.RawRemainder)
+		assert.Equal(t, report.SnapshotTs, delta.BaseSnapshotTs)
+	})
+	t.Run("added, updated and deleted objects", func(t *testing.T) {
+		tracker := newSnapshotDeltaTracker(time.Hour)
+		idx, _, err := tracker.Prepare(newDeltaTestReport(t, "cluster",
+			newDeltaTestPod("1", "pod-1", "Running"),
+			newDeltaTestPod("2", "pod-2", "Running"),
+		))
+		require.NoError(t, err)
+		tracker.Accept(idx, true)
+
+		idx, delta, err := tracker.Prepare(newDeltaTestReport(t, "cluster",
+			newDeltaTestPod("1", "pod-1", "Failed"),
+			newDeltaTestPod("3", "pod-3", "Running"),
+		))
+		require.NoError(t, err)
+		require.NotNil(t, delta)
+		require.Len(t, delta.Deltas, 3)
+		assert.Equal(t, agent.ObjectDelta_UPDATED, delta.Deltas[0].Type)
+		assert.Equal(t, "1", delta.Deltas[0].Uid)
+		assert.Equal(t, "Kubernetes.Pods", delta.Deltas[0].Path)
+		assert.Equal(t, agent.ObjectDelta_DELETED, delta.Deltas[1].Type)
+		assert.Equal(t, "2", delta.Deltas[1].Uid)
+		assert.Empty(t, delta.Deltas[1].RawObject)
+		assert.Equal(t, agent.ObjectDelta_ADDED, delta.Deltas[2].Type)
+		assert.Equal(t, "3", delta.Deltas[2].Uid)
+
+		var pod kates.Pod
+		require.NoError(t, json.Unmarshal(delta.Deltas[0].RawObject, &pod))
+		assert.Equal(t, v1.PodFailed, pod.Status.Phase)
+
+		// once accepted, the same report yields no changes
+		tracker.Accept(idx, false)
+		_, delta, err = tracker.Prepare(newDeltaTestReport(t, "cluster",
+			newDeltaTestPod("1", "pod-1", "Failed"),
+			newDeltaTestPod("3", "pod-3", "Running"),
+		))
+		require.NoError(t, err)
+		require.NotNil(t, delta)
+		assert.Empty(t, delta.Deltas)
+	})
+	t.Run("remainder changes are reported", func(t *testing.T) {
+		tracker := newSnapshotDeltaTracker(time.Hour)
+		idx, _, err := tracker.Prepare(newDeltaTestReport(t, "cluster"))
+		require.NoError(t, err)
+		tracker.Accept(idx, true)
+
+		_, delta, err := tracker.Prepare(newDeltaTestReport(t, "other-cluster"))
+		require.NoError(t, err)
+		require.NotNil(t, delta)
+		var remainder snapshotTypes.Snapshot
+		require.NoError(t, json.Unmarshal(delta.RawRemainder, &remainder))
+		assert.Equal(t, "other-cluster", remainder.AmbassadorMeta.ClusterID)
+	})
+	t.Run("resync requested", func(t *testing.T) {
+		tracker := newSnapshotDeltaTracker(time.Hour)
+		idx, _, err := tracker.Prepare(newDeltaTestReport(t, "cluster"))
+		require.NoError(t, err)
+		tracker.Accept(idx, true)
+		tracker.RequestFullResync()
+
+		_, delta, err := tracker.Prepare(newDeltaTestReport(t, "cluster"))
+		require.NoError(t, err)
+		assert.Nil(t, delta)
+	})
+	t.Run("resync period elapsed", func(t *testing.T) {
+		tracker := newSnapshotDeltaTracker(time.Hour)
+		idx, _, err := tracker.Prepare(newDeltaTestReport(t, "cluster"))
+		require.NoError(t, err)
+		tracker.Accept(idx, true)
+		tracker.lastFull = time.Now().Add(-2 * time.Hour)
+
+		_, delta, err := tracker.Prepare(newDeltaTestReport(t, "cluster"))
+		require.NoError(t, err)
+		assert.Nil(t, delta)
+	})
+	t.Run("disabled by zero period", func(t *testing.T) {
+		tracker := newSnapshotDeltaTracker(0)
+		idx, _, err := tracker.Prepare(newDeltaTestReport(t, "cluster"))
+		require.NoError(t, err)
+		tracker.Accept(idx, true)
+
+		_, delta, err := tracker.Prepare(newDeltaTestReport(t, "cluster"))
+		require.NoError(t, err)
+		assert.Nil(t, delta)
+	})
+}
+
+func TestSendReportDeltas(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+
+	newAgent := func(client *MockClient) *Agent {
+		a := newTestAgent(client)
+		a.snapshotDeltas = newSnapshotDeltaTracker(time.Hour)
+		return a
+	}
+
+	t.Run("sends delta after full snapshot", func(t *testing.T) {
+		client := &MockClient{}
+		a := newAgent(client)
+
+		require.NoError(t, a.sendReport(ctx, newDeltaTestReport(t, "cluster", newDeltaTestPod("1", "pod-1", "Running")), contentEncodingNone, "apikey"))
+		require.NoError(t, a.sendReport(ctx, newDeltaTestReport(t, "cluster", newDeltaTestPod("1", "pod-1", "Failed")), contentEncodingNone, "apikey"))
+
+		assert.Len(t, client.GetSnapshots(), 1)
+		deltas := client.GetDeltas()
+		require.Len(t, deltas, 1)
+		require.Len(t, deltas[0].Deltas, 1)
+		assert.Equal(t, agent.ObjectDelta_UPDATED, deltas[0].Deltas[0].Type)
+	})
+	t.Run("falls back to full snapshots", func(t *testing.T) {
+		client := &MockClient{
+			deltaFunc: func(context.Context, *agent.SnapshotDelta) (*agent.SnapshotResponse, error) {
+				return nil, status.Error(codes.Unimplemented, "unknown method ReportDeltaStream")
+			},
+		}
+		a := newAgent(client)
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, a.sendReport(ctx, newDeltaTestReport(t, "cluster"), contentEncodingNone, "apikey"))
+		}
+
+		assert.Len(t, client.GetSnapshots(), 3)
+		assert.Len(t, client.GetDeltas(), 1)
+		assert.True(t, a.snapshotDeltas.unsupported)
+	})
+	t.Run("failed delta is not accepted", func(t *testing.T) {
+		client := &MockClient{}
+		a := newAgent(client)
+		require.NoError(t, a.sendReport(ctx, newDeltaTestReport(t, "cluster"), contentEncodingNone, "apikey"))
+
+		client.deltaFunc = func(context.Context, *agent.SnapshotDelta) (*agent.SnapshotResponse, error) {
+			return nil, status.Error(codes.Unavailable, "connection refused")
+		}
+		assert.Error(t, a.sendReport(ctx, newDeltaTestReport(t, "cluster", newDeltaTestPod("1", "pod-1", "Running")), contentEncodingNone, "apikey"))
+
+		client.deltaFunc = nil
+		require.NoError(t, a.sendReport(ctx, newDeltaTestReport(t, "cluster", newDeltaTestPod("1", "pod-1", "Running")), contentEncodingNone, "apikey"))
+		deltas := client.GetDeltas()
+		require.Len(t, deltas, 2)
+		require.Len(t, deltas[1].Deltas, 1)
+		assert.Equal(t, agent.ObjectDelta_ADDED, deltas[1].Deltas[0].Type)
+	})
+	t.Run("delta is encoded like the full snapshot", func(t *testing.T) {
+		client := &MockClient{}
+		a := newAgent(client)
+
+		require.NoError(t, a.sendReport(ctx, newDeltaTestReport(t, "cluster", newDeltaTestPod("1", "pod-1", "Running")), contentEncodingGzip, "apikey"))
+		require.NoError(t, a.sendReport(ctx, newDeltaTestReport(t, "other-cluster",
+			newDeltaTestPod("1", "pod-1", "Failed"),
+			newDeltaTestPod("2", "pod-2", "Running"),
+		), contentEncodingGzip, "apikey"))
+
+		deltas := client.GetDeltas()
+		require.Len(t, deltas, 1)
+		delta := deltas[0]
+		assert.Equal(t, snapshotTypes.ContentTypeJSON+"+gzip", delta.ContentType)
+		raw, err := decodeContent(delta.ContentType, delta.RawRemainder)
+		require.NoError(t, err)
+		var remainder snapshotTypes.Snapshot
+		require.NoError(t, json.Unmarshal(raw, &remainder))
+		assert.Equal(t, "other-cluster", remainder.AmbassadorMeta.ClusterID)
+		require.Len(t, delta.Deltas, 2)
+		raw, err = decodeContent(delta.ContentType, delta.Deltas[0].RawObject)
+		require.NoError(t, err)
+		var pod kates.Pod
+		require.NoError(t, json.Unmarshal(raw, &pod))
+		assert.Equal(t, v1.PodFailed, pod.Status.Phase)
+	})
+}
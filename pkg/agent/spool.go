@@ -0,0 +1,320 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/datawire/ambassador-agent/pkg/api/agent"
+	"github.com/datawire/dlib/dlog"
+)
+
+const (
+	spoolSnapshotFile   = "snapshot.json"
+	spoolResultsDir     = "command-results"
+	spoolResultSuffix   = ".json"
+	spoolTempFilePrefix = ".tmp-"
+)
+
+// reportSpool keeps what could not be sent to the Director on disk, so that it
+// can be sent once the Director can be reached again, even after a restart.
+// It holds the latest snapshot and, in order, a bounded number of command
+// results.
+type reportSpool struct {
+	dir        string
+	maxResults int
+
+	mu          sync.Mutex
+	hasSnapshot bool
+	results     []uint64 // sequence numbers of the spooled results, oldest first
+	nextSeq     uint64
+}
+
+// spooledCommandResult is a command result read back from the spool.
+type spooledCommandResult struct {
+	seq    uint64
+	result *agent.CommandResult
+}
+
+// newReportSpool returns a spool in dir, picking up whatever a previous agent
+// left there.
+func newReportSpool(dir string, maxResults int) (*reportSpool, error) {
+	s := &reportSpool{dir: dir, maxResults: maxResults}
+	if err := os.MkdirAll(filepath.Join(dir, spoolResultsDir), 0o700); err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, spoolSnapshotFile)); err == nil {
+		s.hasSnapshot = true
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, spoolResultsDir))
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, spoolResultSuffix) || strings.HasPrefix(name, spoolTempFilePrefix) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, spoolResultSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		s.results = append(s.results, seq)
+	}
+	sort.Slice(s.results, func(i, j int) bool { return s.results[i] < s.results[j] })
+	if n := len(s.results); n > 0 {
+		s.nextSeq = s.results[n-1] + 1
+	}
+	return s, nil
+}
+
+func (s *reportSpool) resultPath(seq uint64) string {
+	return filepath.Join(s.dir, spoolResultsDir, fmt.Sprintf("%020d%s", seq, spoolResultSuffix))
+}
+
+// HasSnapshot tells whether a snapshot is waiting to be sent.
+func (s *reportSpool) HasSnapshot() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hasSnapshot
+}
+
+// HasCommandResults tells whether command results are waiting to be sent.
+func (s *reportSpool) HasCommandResults() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.results) > 0
+}
+
+// SaveSnapshot replaces the spooled snapshot with the given report.
+func (s *reportSpool) SaveSnapshot(report *agent.Snapshot) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := writeFileAtomic(filepath.Join(s.dir, spoolSnapshotFile), data); err != nil {
+		return err
+	}
+	s.hasSnapshot = true
+	return nil
+}
+
+// Snapshot returns the spooled snapshot, or nil if there is none.
+func (s *reportSpool) Snapshot() (*agent.Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.hasSnapshot {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.dir, spoolSnapshotFile))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			s.hasSnapshot = false
+			return nil, nil
+		}
+		return nil, err
+	}
+	report := &agent.Snapshot{}
+	if err := json.Unmarshal(data, report); err != nil {
+		// Nothing we can do with it; don't try again
+		s.removeSnapshot()
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	return report, nil
+}
+
+// ClearSnapshot removes the spooled snapshot.
+func (s *reportSpool) ClearSnapshot() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.hasSnapshot {
+		return nil
+	}
+	return s.removeSnapshot()
+}
+
+func (s *reportSpool) removeSnapshot() error {
+	s.hasSnapshot = false
+	if err := os.Remove(filepath.Join(s.dir, spoolSnapshotFile)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// AppendCommandResult adds the result after the ones already spooled. The
+// oldest results are dropped when there are more than maxResults. It returns
+// the number of results that were dropped.
+func (s *reportSpool) AppendCommandResult(result *agent.CommandResult) (int, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return 0, fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seq := s.nextSeq
+	if err := writeFileAtomic(s.resultPath(seq), data); err != nil {
+		return 0, err
+	}
+	s.nextSeq++
+	s.results = append(s.results, seq)
+
+	dropped := 0
+	for len(s.results) > s.maxResults {
+		if err := s.removeCommandResult(s.results[0]); err != nil {
+			return dropped, err
+		}
+		dropped++
+	}
+	return dropped, nil
+}
+
+// CommandResults returns the spooled command results, oldest first.
+func (s *reportSpool) CommandResults() ([]spooledCommandResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	results := make([]spooledCommandResult, 0, len(s.results))
+	for _, seq := range append([]uint64(nil), s.results...) {
+		data, err := os.ReadFile(s.resultPath(seq))
+		if err != nil {
+			return nil, err
+		}
+		result := &agent.CommandResult{}
+		if err := json.Unmarshal(data, result); err != nil {
+			// Nothing we can do with it; skip it for good
+			if err := s.removeCommandResult(seq); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		results = append(results, spooledCommandResult{seq: seq, result: result})
+	}
+	return results, nil
+}
+
+// RemoveCommandResult removes a result returned by CommandResults once it
+// has been sent.
+func (s *reportSpool) RemoveCommandResult(r spooledCommandResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.removeCommandResult(r.seq)
+}
+
+func (s *reportSpool) removeCommandResult(seq uint64) error {
+	for i, v := range s.results {
+		if v == seq {
+			s.results = append(s.results[:i], s.results[i+1:]...)
+			break
+		}
+	}
+	if err := os.Remove(s.resultPath(seq)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// writeFileAtomic makes sure that a reader never sees a partially written file,
+// even if the agent is killed while writing it.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), spoolTempFilePrefix)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// ReportCommandResult sends the result of a command to the Director. When the
+// spool is enabled, the result is spooled first and sent after those that
+// failed to send before, so that the Director gets them in order.
+func (a *Agent) ReportCommandResult(ctx context.Context, result *agent.CommandResult) error {
+	if a.spool == nil {
+		return a.comm.ReportCommandResult(ctx, result, a.AmbassadorAPIKey)
+	}
+	dropped, err := a.spool.AppendCommandResult(result)
+	if dropped > 0 {
+		dlog.Warnf(ctx, "spool is full, dropped %d command results", dropped)
+	}
+	if err != nil {
+		dlog.Warnf(ctx, "unable to spool command result: %v", err)
+		return a.comm.ReportCommandResult(ctx, result, a.AmbassadorAPIKey)
+	}
+	return a.replayCommandResults(ctx)
+}
+
+// replayCommandResults sends the spooled command results, oldest first, and
+// stops at the first one that fails to send.
+func (a *Agent) replayCommandResults(ctx context.Context) error {
+	results, err := a.spool.CommandResults()
+	if err != nil {
+		return fmt.Errorf("unable to read spooled command results: %w", err)
+	}
+	for _, r := range results {
+		if err := a.comm.ReportCommandResult(ctx, r.result, a.AmbassadorAPIKey); err != nil {
+			return fmt.Errorf("command result %s kept in spool: %w", r.result.CommandId, err)
+		}
+		if err := a.spool.RemoveCommandResult(r); err != nil {
+			return fmt.Errorf("unable to remove spooled command result: %w", err)
+		}
+	}
+	return nil
+}
+
+// replaySpool sends what the spool holds now that the connection to the
+// Director is back. The spooled snapshot is only used when there is no
+// fresher report to send.
+func (a *Agent) replaySpool(ctx context.Context) {
+	if a.comm.State() == ConnStateReady && a.spool.HasCommandResults() {
+		if err := a.replayCommandResults(ctx); err != nil {
+			dlog.Warnf(ctx, "failed to replay command results: %v", err)
+		}
+	}
+
+	if a.reportToSend == nil && !a.reportRunning.Load() && a.spool.HasSnapshot() {
+		report, err := a.spool.Snapshot()
+		if err != nil {
+			dlog.Warnf(ctx, "unable to read spooled snapshot: %v", err)
+			return
+		}
+		if report != nil {
+			dlog.Debug(ctx, "Sending spooled snapshot")
+			a.reportToSend = report
+		}
+	}
+}
+
+// spoolReport keeps the report in the spool if it couldn't be sent, and
+// otherwise forgets any report sent before.
+func (a *Agent) spoolReport(ctx context.Context, report *agent.Snapshot, reportErr error) {
+	var err error
+	if reportErr != nil {
+		err = a.spool.SaveSnapshot(report)
+	} else {
+		err = a.spool.ClearSnapshot()
+	}
+	if err != nil {
+		dlog.Warnf(ctx, "unable to update the snapshot spool: %v", err)
+	}
+}
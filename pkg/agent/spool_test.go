@@ -0,0 +1,128 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/datawire/ambassador-agent/pkg/api/agent"
+	"github.com/datawire/dlib/dlog"
+)
+
+func spooledCommandIDs(t *testing.T, s *reportSpool) []string {
+	results, err := s.CommandResults()
+	require.NoError(t, err)
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.result.CommandId
+	}
+	return ids
+}
+
+func TestReportSpool(t *testing.T) {
+	t.Run("snapshot survives restart", func(t *testing.T) {
+		dir := t.TempDir()
+		s, err := newReportSpool(dir, 10)
+		require.NoError(t, err)
+		assert.False(t, s.HasSnapshot())
+
+		require.NoError(t, s.SaveSnapshot(&agent.Snapshot{Message: "first"}))
+		require.NoError(t, s.SaveSnapshot(&agent.Snapshot{Message: "second"}))
+
+		s, err = newReportSpool(dir, 10)
+		require.NoError(t, err)
+		require.True(t, s.HasSnapshot())
+		report, err := s.Snapshot()
+		require.NoError(t, err)
+		assert.Equal(t, "second", report.Message)
+
+		require.NoError(t, s.ClearSnapshot())
+		assert.False(t, s.HasSnapshot())
+		report, err = s.Snapshot()
+		require.NoError(t, err)
+		assert.Nil(t, report)
+	})
+	t.Run("command results are bounded and ordered", func(t *testing.T) {
+		dir := t.TempDir()
+		s, err := newReportSpool(dir, 3)
+		require.NoError(t, err)
+		for _, id := range []string{"a", "b", "c", "d"} {
+			dropped, err := s.AppendCommandResult(&agent.CommandResult{CommandId: id})
+			require.NoError(t, err)
+			if id == "d" {
+				assert.Equal(t, 1, dropped)
+			}
+		}
+		assert.Equal(t, []string{"b", "c", "d"}, spooledCommandIDs(t, s))
+
+		// the order is kept across restarts, and new results go last
+		s, err = newReportSpool(dir, 3)
+		require.NoError(t, err)
+		_, err = s.AppendCommandResult(&agent.CommandResult{CommandId: "e"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"c", "d", "e"}, spooledCommandIDs(t, s))
+	})
+	t.Run("corrupt files are discarded", func(t *testing.T) {
+		dir := t.TempDir()
+		s, err := newReportSpool(dir, 3)
+		require.NoError(t, err)
+		require.NoError(t, s.SaveSnapshot(&agent.Snapshot{}))
+		_, err = s.AppendCommandResult(&agent.CommandResult{CommandId: "a"})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, spoolSnapshotFile), []byte("{"), 0o600))
+		require.NoError(t, os.WriteFile(s.resultPath(0), []byte("{"), 0o600))
+
+		_, err = s.Snapshot()
+		assert.Error(t, err)
+		assert.False(t, s.HasSnapshot())
+		assert.Empty(t, spooledCommandIDs(t, s))
+		assert.False(t, s.HasCommandResults())
+	})
+}
+
+func TestSpoolReplay(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	unavailable := func(context.Context, *agent.CommandResult) (*agent.CommandResultResponse, error) {
+		return nil, status.Error(codes.Unavailable, "connection refused")
+	}
+	client := &MockClient{resultFunc: unavailable}
+	spool, err := newReportSpool(t.TempDir(), 10)
+	require.NoError(t, err)
+	a := newTestAgent(client)
+	a.spool = spool
+	comm := a.comm.(*RPCComm)
+
+	// results are kept while the Director can't be reached
+	assert.Error(t, a.ReportCommandResult(ctx, &agent.CommandResult{CommandId: "a"}))
+	assert.Error(t, a.ReportCommandResult(ctx, &agent.CommandResult{CommandId: "b"}))
+	a.spoolReport(ctx, &agent.Snapshot{Message: "unsent"}, status.Error(codes.Unavailable, "connection refused"))
+	assert.Equal(t, ConnStateDegraded, comm.State())
+	assert.Empty(t, client.GetResults())
+
+	// the spooled snapshot is sent when there's no fresher report
+	a.replaySpool(ctx)
+	require.NotNil(t, a.reportToSend)
+	assert.Equal(t, "unsent", a.reportToSend.Message)
+	assert.Empty(t, client.GetResults())
+
+	// once the connection is back, results are replayed in order
+	client.resultFunc = nil
+	comm.observe(ctx, nil)
+	a.replaySpool(ctx)
+	require.NoError(t, a.ReportCommandResult(ctx, &agent.CommandResult{CommandId: "c"}))
+	results := client.GetResults()
+	require.Len(t, results, 3)
+	for i, id := range []string{"a", "b", "c"} {
+		assert.Equal(t, id, results[i].CommandId)
+	}
+	assert.False(t, spool.HasCommandResults())
+
+	a.spoolReport(ctx, a.reportToSend, nil)
+	assert.False(t, spool.HasSnapshot())
+}
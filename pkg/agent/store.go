@@ -5,6 +5,7 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 
+	"github.com/datawire/ambassador-agent/pkg/agent/watchers"
 	"github.com/emissary-ingress/emissary/v3/pkg/kates"
 )
 
@@ -30,7 +31,7 @@ func NewPodStore(pods []*kates.Pod) *podStore {
 	store := &podStore{sotw: sotw}
 
 	for _, pod := range pods {
-		if allowedNamespace(pod.GetNamespace()) && pod.Status.Phase != v1.PodSucceeded {
+		if watchers.AllowedNamespace(pod.GetNamespace()) && pod.Status.Phase != v1.PodSucceeded {
 			key := fmt.Sprintf("%s.%s", pod.GetName(), pod.GetNamespace())
 			store.sotw[key] = pod
 		}
@@ -44,7 +45,7 @@ func NewConfigMapStore(cms []*kates.ConfigMap) *configMapStore {
 	store := &configMapStore{sotw: sotw}
 
 	for _, cm := range cms {
-		if allowedNamespace(cm.GetNamespace()) {
+		if watchers.AllowedNamespace(cm.GetNamespace()) {
 			key := fmt.Sprintf("%s.%s", cm.GetName(), cm.GetNamespace())
 			store.sotw[key] = cm
 		}
@@ -58,7 +59,7 @@ func NewDeploymentStore(ds []*kates.Deployment) *deploymentStore {
 	store := &deploymentStore{sotw: sotw}
 
 	for _, d := range ds {
-		if allowedNamespace(d.GetNamespace()) {
+		if watchers.AllowedNamespace(d.GetNamespace()) {
 			key := fmt.Sprintf("%s.%s", d.GetName(), d.GetNamespace())
 			store.sotw[key] = d
 		}
@@ -72,7 +73,7 @@ func NewEndpointsStore(es []*kates.Endpoints) *endpointStore {
 	store := &endpointStore{sotw: sotw}
 
 	for _, ep := range es {
-		if allowedNamespace(ep.GetNamespace()) {
+		if watchers.AllowedNamespace(ep.GetNamespace()) {
 			key := fmt.Sprintf("%s.%s", ep.GetName(), ep.GetNamespace())
 			store.sotw[key] = ep
 		}
@@ -114,9 +115,3 @@ func (store *deploymentStore) StateOfWorld() []*kates.Deployment {
 	}
 	return deployments
 }
-
-// allowedNamespace will check if resources from the given namespace
-// should be reported to Ambassador Cloud.
-func allowedNamespace(namespace string) bool {
-	return namespace != "kube-system"
-}
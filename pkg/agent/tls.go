@@ -0,0 +1,107 @@
+package agent
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// parseTLSVersion parses a TLS version such as "1.2".
+func parseTLSVersion(str string) (uint16, error) {
+	switch str {
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("invalid TLS version %q, must be one of 1.0, 1.1, 1.2 or 1.3", str)
+	}
+}
+
+// directorTLSConfig returns the TLS configuration for the connection to the
+// Director. The CA bundle is read right away, so that a new connection picks up
+// a new bundle, while the client certificate is reloaded whenever it changes.
+func (e *Env) directorTLSConfig() (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: e.RPCTLSMinVersion}
+
+	if e.RPCTLSCAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(e.RPCTLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read CA bundle: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", e.RPCTLSCAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	switch {
+	case e.RPCTLSCertFile == "" && e.RPCTLSKeyFile == "":
+	case e.RPCTLSCertFile == "" || e.RPCTLSKeyFile == "":
+		return nil, errors.New("RPC_TLS_CERT_FILE and RPC_TLS_KEY_FILE must be set together")
+	default:
+		reloader := &clientCertReloader{certFile: e.RPCTLSCertFile, keyFile: e.RPCTLSKeyFile}
+		// Fail early rather than on the first handshake
+		if _, err := reloader.GetClientCertificate(nil); err != nil {
+			return nil, err
+		}
+		cfg.GetClientCertificate = reloader.GetClientCertificate
+	}
+	return cfg, nil
+}
+
+// clientCertReloader loads a client certificate and its key from files, and
+// loads them again when they change, e.g. when a mounted Secret is updated.
+type clientCertReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	certMod time.Time
+	keyMod  time.Time
+}
+
+// GetClientCertificate implements tls.Config.GetClientCertificate.
+func (r *clientCertReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	certInfo, certErr := os.Stat(r.certFile)
+	keyInfo, keyErr := os.Stat(r.keyFile)
+	if err := errors.Join(certErr, keyErr); err != nil {
+		if r.cert != nil {
+			// Keep the certificate that we have until the files are back
+			return r.cert, nil
+		}
+		return nil, fmt.Errorf("unable to load client certificate: %w", err)
+	}
+	if r.cert != nil && certInfo.ModTime().Equal(r.certMod) && keyInfo.ModTime().Equal(r.keyMod) {
+		return r.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		if r.cert != nil {
+			// The files may be halfway through an update; try again next time
+			return r.cert, nil
+		}
+		return nil, fmt.Errorf("unable to load client certificate: %w", err)
+	}
+	r.cert = &cert
+	r.certMod = certInfo.ModTime()
+	r.keyMod = keyInfo.ModTime()
+	return r.cert, nil
+}
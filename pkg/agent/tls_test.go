@@ -0,0 +1,165 @@
+package agent
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+	kpem []byte
+}
+
+func newTestCert(t *testing.T, cn string, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{cn},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return &testCert{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		kpem: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+// writeTestCert writes the certificate and key, making sure that their
+// modification time differs from the one of the files they replace.
+func writeTestCert(t *testing.T, c *testCert, certFile, keyFile string, modTime time.Time) {
+	require.NoError(t, os.WriteFile(certFile, c.pem, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, c.kpem, 0o600))
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	v, err := parseTLSVersion("1.3")
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), v)
+
+	_, err = parseTLSVersion("1.4")
+	assert.Error(t, err)
+}
+
+func TestDirectorTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "test-ca", nil)
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, ca.pem, 0o600))
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	client := newTestCert(t, "client", ca)
+	writeTestCert(t, client, certFile, keyFile, time.Now().Add(-time.Minute))
+
+	t.Run("invalid settings", func(t *testing.T) {
+		_, err := (&Env{RPCTLSCertFile: certFile}).directorTLSConfig()
+		assert.Error(t, err)
+		_, err = (&Env{RPCTLSCAFile: certFile + ".missing"}).directorTLSConfig()
+		assert.Error(t, err)
+		_, err = (&Env{RPCTLSCAFile: keyFile}).directorTLSConfig()
+		assert.Error(t, err)
+	})
+
+	t.Run("mutual TLS", func(t *testing.T) {
+		server := newTestCert(t, "localhost", ca)
+		clientCAs := x509.NewCertPool()
+		clientCAs.AddCert(ca.cert)
+		ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+			Certificates: []tls.Certificate{{Certificate: [][]byte{server.cert.Raw}, PrivateKey: server.key}},
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+			ClientCAs:    clientCAs,
+			MinVersion:   tls.VersionTLS12,
+		})
+		require.NoError(t, err)
+		defer ln.Close()
+		peers := make(chan string, 1)
+		go func() {
+			for {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				tc := conn.(*tls.Conn)
+				if err := tc.Handshake(); err == nil {
+					peers <- tc.ConnectionState().PeerCertificates[0].Subject.CommonName
+				}
+				tc.Close()
+			}
+		}()
+
+		env := &Env{
+			RPCTLSCAFile:     caFile,
+			RPCTLSCertFile:   certFile,
+			RPCTLSKeyFile:    keyFile,
+			RPCTLSMinVersion: tls.VersionTLS12,
+		}
+		cfg, err := env.directorTLSConfig()
+		require.NoError(t, err)
+		cfg.ServerName = "localhost"
+
+		dial := func() {
+			conn, err := tls.Dial("tcp", ln.Addr().String(), cfg)
+			require.NoError(t, err)
+			defer conn.Close()
+			require.NoError(t, conn.Handshake())
+		}
+		dial()
+		assert.Equal(t, "client", <-peers)
+
+		// the rotated certificate is used by the next handshake
+		writeTestCert(t, newTestCert(t, "rotated-client", ca), certFile, keyFile, time.Now())
+		dial()
+		assert.Equal(t, "rotated-client", <-peers)
+	})
+
+	t.Run("certificate kept while files are updated", func(t *testing.T) {
+		r := &clientCertReloader{certFile: certFile, keyFile: keyFile}
+		first, err := r.GetClientCertificate(nil)
+		require.NoError(t, err)
+
+		// only the certificate has been replaced so far
+		other := newTestCert(t, "other", ca)
+		require.NoError(t, os.WriteFile(certFile, other.pem, 0o600))
+		require.NoError(t, os.Chtimes(certFile, time.Now().Add(time.Minute), time.Now().Add(time.Minute)))
+		cert, err := r.GetClientCertificate(nil)
+		require.NoError(t, err)
+		assert.Same(t, first, cert)
+	})
+}
@@ -0,0 +1,183 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/datawire/ambassador-agent/pkg/agent/watchers"
+	"github.com/datawire/dlib/dlog"
+	"github.com/datawire/k8sapi/pkg/k8sapi"
+	"github.com/emissary-ingress/emissary/v3/pkg/kates"
+)
+
+// unstructuredStore holds the state of the world of a resource watched with
+// WatchGeneric, in the namespaces that are reported.
+type unstructuredStore struct {
+	mux  sync.Mutex
+	sotw []*unstructured.Unstructured
+	// synced is set once the store got the state of the world of its resource.
+	synced bool
+}
+
+// FromCallback replaces the state of the world with the one of the callback.
+func (s *unstructuredStore) FromCallback(callback *GenericCallback) error {
+	sotw := make([]*unstructured.Unstructured, 0, len(callback.Sotw))
+	for _, obj := range callback.Sotw {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return fmt.Errorf("unstructuredStore error: obj is %T: expected unstructured.Unstructured", obj)
+		}
+		if watchers.AllowedNamespace(u.GetNamespace()) {
+			sotw = append(sotw, u)
+		}
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.sotw = sotw
+	s.synced = true
+	return nil
+}
+
+// Reset empties the store, e.g. when its resource is no longer served.
+func (s *unstructuredStore) Reset() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.sotw = nil
+	s.synced = false
+}
+
+// StateOfWorld returns the resources in the store.
+func (s *unstructuredStore) StateOfWorld() []*unstructured.Unstructured {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return append([]*unstructured.Unstructured(nil), s.sotw...)
+}
+
+// Synced tells whether the store holds the state of the world of its resource,
+// i.e. whether the resource is watched, even if there is none in the cluster.
+func (s *unstructuredStore) Synced() bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.synced
+}
+
+// watchUnstructured keeps the store up to date with the resources of gvr until
+// the context is cancelled.
+func watchUnstructured(ctx context.Context, dc *DynamicClient, gvr *schema.GroupVersionResource, store *unstructuredStore) {
+	callbackCh := dc.WatchGeneric(ctx, kates.NamespaceAll, gvr)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case callback, ok := <-callbackCh:
+			if !ok {
+				return
+			}
+			dlog.Debugf(ctx, "%s callback: %v", gvr, callback.EventType)
+			if err := store.FromCallback(callback); err != nil {
+				dlog.Warnf(ctx, "Error processing %s callback: %s", gvr, err)
+			}
+		}
+	}
+}
+
+// serverResources returns the resources that the cluster serves. It returns what
+// could be discovered when some API groups are unavailable.
+func serverResources(ctx context.Context) []*metav1.APIResourceList {
+	_, resourceLists, err := k8sapi.GetK8sInterface(ctx).Discovery().ServerGroupsAndResources()
+	if err != nil && len(resourceLists) == 0 {
+		dlog.Errorf(ctx, "Error getting resources list: %s", err)
+	}
+	return resourceLists
+}
+
+// servedResource returns the first of the versions of the resource that the
+// cluster serves, or nil when it serves none of them.
+func servedResource(resourceLists []*metav1.APIResourceList, group, resource string, versions ...string) *schema.GroupVersionResource {
+	for _, version := range versions {
+		gvr := schema.GroupVersionResource{Group: group, Version: version, Resource: resource}
+		for _, rl := range resourceLists {
+			if rl.GroupVersion != gvr.GroupVersion().String() {
+				continue
+			}
+			for _, ar := range rl.APIResources {
+				if ar.Name == resource {
+					return &gvr
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// unstructuredWatches runs the watches of resources that come and go as their
+// CRDs are installed, upgraded or removed.
+type unstructuredWatches struct {
+	client   *kates.Client
+	watching map[string]schema.GroupVersionResource
+	cancels  map[string]context.CancelFunc
+	// done is closed when the goroutine of a watch has returned, after which
+	// its store is no longer written to.
+	done map[string]chan struct{}
+}
+
+func newUnstructuredWatches() (*unstructuredWatches, error) {
+	client, err := kates.NewClient(kates.ClientConfig{})
+	if err != nil {
+		return nil, err
+	}
+	return &unstructuredWatches{
+		client:   client,
+		watching: make(map[string]schema.GroupVersionResource),
+		cancels:  make(map[string]context.CancelFunc),
+		done:     make(map[string]chan struct{}),
+	}, nil
+}
+
+// update makes the store of the named resource hold the resources of gvr, which is
+// nil when the cluster doesn't serve the resource. The watch is restarted when the
+// version changes, and stopped, and the store emptied, when gvr is nil.
+func (w *unstructuredWatches) update(ctx context.Context, name string, gvr *schema.GroupVersionResource, store *unstructuredStore) {
+	current, ok := w.watching[name]
+	if ok && gvr != nil && current == *gvr {
+		return
+	}
+	if ok {
+		// the store is reset once the watch is done with it, or a callback in
+		// flight would fill it again with the resources of the old version
+		w.cancels[name]()
+		<-w.done[name]
+		delete(w.cancels, name)
+		delete(w.done, name)
+		delete(w.watching, name)
+		store.Reset()
+	}
+	if gvr == nil {
+		dlog.Debugf(ctx, "Will not watch %s because that resource is not known to this cluster", name)
+		return
+	}
+	dlog.Infof(ctx, "Watching %s", gvr)
+	// Every watch has its own client, as cancelling a watch stops the callbacks of its client
+	dc := NewDynamicClient(w.client.DynamicInterface(), NewK8sInformer)
+	var cctx context.Context
+	cctx, w.cancels[name] = context.WithCancel(ctx)
+	w.watching[name] = *gvr
+	done := make(chan struct{})
+	w.done[name] = done
+	go func() {
+		defer close(done)
+		watchUnstructured(cctx, dc, gvr, store)
+	}()
+}
+
+// cancel stops all the watches.
+func (w *unstructuredWatches) cancel() {
+	for _, cancel := range w.cancels {
+		cancel()
+	}
+}
@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestServedResource(t *testing.T) {
+	resourceLists := []*metav1.APIResourceList{
+		{GroupVersion: "gateway.networking.k8s.io/v1beta1", APIResources: []metav1.APIResource{{Name: "gateways"}, {Name: "httproutes"}}},
+		{GroupVersion: "gateway.networking.k8s.io/v1", APIResources: []metav1.APIResource{{Name: "gateways"}}},
+		{GroupVersion: "gateway.networking.k8s.io/v1alpha2", APIResources: []metav1.APIResource{{Name: "grpcroutes"}}},
+	}
+
+	assert.Equal(t, &schema.GroupVersionResource{Group: gatewayAPIGroup, Version: "v1", Resource: "gateways"},
+		servedResource(resourceLists, gatewayAPIGroup, "gateways", "v1", "v1beta1"))
+	assert.Equal(t, &schema.GroupVersionResource{Group: gatewayAPIGroup, Version: "v1beta1", Resource: "httproutes"},
+		servedResource(resourceLists, gatewayAPIGroup, "httproutes", "v1", "v1beta1"))
+	assert.Equal(t, &schema.GroupVersionResource{Group: gatewayAPIGroup, Version: "v1alpha2", Resource: "grpcroutes"},
+		servedResource(resourceLists, gatewayAPIGroup, "grpcroutes", "v1", "v1alpha2"))
+	assert.Nil(t, servedResource(resourceLists, gatewayAPIGroup, "tlsroutes", "v1", "v1alpha2"))
+}
+
+func TestUnstructuredStore(t *testing.T) {
+	newObj := func(name, namespace string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetName(name)
+		u.SetNamespace(namespace)
+		return u
+	}
+	s := &unstructuredStore{}
+	require.NoError(t, s.FromCallback(&GenericCallback{
+		EventType: CallbackEventAdded,
+		Sotw:      []interface{}{newObj("a", "default"), newObj("b", "kube-system")},
+	}))
+	assert.True(t, s.Synced())
+	sotw := s.StateOfWorld()
+	require.Len(t, sotw, 1)
+	assert.Equal(t, "a", sotw[0].GetName())
+
+	assert.Error(t, s.FromCallback(&GenericCallback{Sotw: []interface{}{"not an object"}}))
+
+	s.Reset()
+	assert.Empty(t, s.StateOfWorld())
+	assert.False(t, s.Synced())
+}
+
+func TestUnstructuredWatches_Update(t *testing.T) {
+	ctx := context.Background()
+	gvr := schema.GroupVersionResource{Group: gatewayAPIGroup, Version: "v1beta1", Resource: "gateways"}
+	store := &unstructuredStore{}
+
+	// a watch that is still handling a callback when it is cancelled
+	wctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-wctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		u := &unstructured.Unstructured{}
+		u.SetName("old")
+		assert.NoError(t, store.FromCallback(&GenericCallback{Sotw: []interface{}{u}}))
+	}()
+	w := &unstructuredWatches{
+		watching: map[string]schema.GroupVersionResource{"gateways": gvr},
+		cancels:  map[string]context.CancelFunc{"gateways": cancel},
+		done:     map[string]chan struct{}{"gateways": done},
+	}
+
+	w.update(ctx, "gateways", nil, store)
+	<-done
+	assert.Empty(t, store.StateOfWorld())
+	assert.False(t, store.Synced())
+	assert.Empty(t, w.watching)
+}
@@ -5,11 +5,11 @@ import (
 	"sync"
 
 	apps "k8s.io/api/apps/v1"
+	batch "k8s.io/api/batch/v1"
 	core "k8s.io/api/core/v1"
 
 	"github.com/datawire/dlib/dlog"
 	"github.com/datawire/k8sapi/pkg/k8sapi"
-	snapshotTypes "github.com/emissary-ingress/emissary/v3/pkg/snapshot/v1"
 )
 
 type CoreWatchers struct {
@@ -19,13 +19,20 @@ type CoreWatchers struct {
 	podWatchers      k8sapi.WatcherGroup[*core.Pod]
 	endpointWatchers k8sapi.WatcherGroup[*core.Endpoints]
 
-	om ObjectModifier
+	statefulSetWatchers k8sapi.WatcherGroup[*apps.StatefulSet]
+	daemonSetWatchers   k8sapi.WatcherGroup[*apps.DaemonSet]
+	jobWatchers         k8sapi.WatcherGroup[*batch.Job]
+	cronJobWatchers     k8sapi.WatcherGroup[*batch.CronJob]
+
+	om         ObjectModifier
+	exclusions *Exclusions
 }
 
-func NewCoreWatchers(ctx context.Context, namespaces []string, om ObjectModifier) *CoreWatchers {
+func NewCoreWatchers(ctx context.Context, namespaces []string, om ObjectModifier, exclusions *Exclusions) *CoreWatchers {
 	k8sif := k8sapi.GetK8sInterface(ctx)
 	appClient := k8sif.AppsV1().RESTClient()
 	coreClient := k8sif.CoreV1().RESTClient()
+	batchClient := k8sif.BatchV1().RESTClient()
 
 	cond := &sync.Cond{
 		L: &sync.Mutex{},
@@ -37,6 +44,8 @@ func NewCoreWatchers(ctx context.Context, namespaces []string, om ObjectModifier
 		namespaces = append(namespaces, "")
 	}
 
+	exclusions.forwardTo(cond)
+
 	coreWatchers := &CoreWatchers{
 		cmapsWatchers:    k8sapi.NewWatcherGroup[*core.ConfigMap](),
 		deployWatchers:   k8sapi.NewWatcherGroup[*apps.Deployment](),
@@ -44,6 +53,12 @@ func NewCoreWatchers(ctx context.Context, namespaces []string, om ObjectModifier
 		endpointWatchers: k8sapi.NewWatcherGroup[*core.Endpoints](),
 		cond:             cond,
 		om:               om,
+		exclusions:       exclusions,
+
+		statefulSetWatchers: k8sapi.NewWatcherGroup[*apps.StatefulSet](),
+		daemonSetWatchers:   k8sapi.NewWatcherGroup[*apps.DaemonSet](),
+		jobWatchers:         k8sapi.NewWatcherGroup[*batch.Job](),
+		cronJobWatchers:     k8sapi.NewWatcherGroup[*batch.CronJob](),
 	}
 
 	// TODO equals func to prevent over-broadcasting
@@ -52,6 +67,10 @@ func NewCoreWatchers(ctx context.Context, namespaces []string, om ObjectModifier
 		_ = coreWatchers.deployWatchers.AddWatcher(k8sapi.NewWatcher[*apps.Deployment]("deployments", appClient, cond, k8sapi.WithNamespace[*apps.Deployment](ns)))
 		_ = coreWatchers.podWatchers.AddWatcher(k8sapi.NewWatcher[*core.Pod]("pods", coreClient, cond, k8sapi.WithNamespace[*core.Pod](ns)))
 		_ = coreWatchers.endpointWatchers.AddWatcher(k8sapi.NewWatcher[*core.Endpoints]("endpoints", coreClient, cond, k8sapi.WithNamespace[*core.Endpoints](ns)))
+		_ = coreWatchers.statefulSetWatchers.AddWatcher(k8sapi.NewWatcher[*apps.StatefulSet]("statefulsets", appClient, cond, k8sapi.WithNamespace[*apps.StatefulSet](ns)))
+		_ = coreWatchers.daemonSetWatchers.AddWatcher(k8sapi.NewWatcher[*apps.DaemonSet]("daemonsets", appClient, cond, k8sapi.WithNamespace[*apps.DaemonSet](ns)))
+		_ = coreWatchers.jobWatchers.AddWatcher(k8sapi.NewWatcher[*batch.Job]("jobs", batchClient, cond, k8sapi.WithNamespace[*batch.Job](ns)))
+		_ = coreWatchers.cronJobWatchers.AddWatcher(k8sapi.NewWatcher[*batch.CronJob]("cronjobs", batchClient, cond, k8sapi.WithNamespace[*batch.CronJob](ns)))
 	}
 
 	return coreWatchers
@@ -64,9 +83,10 @@ func (w *CoreWatchers) loadPods(ctx context.Context) []*core.Pod {
 		return nil
 	}
 
+	reported := w.exclusions.Reported(ctx)
 	fpods := make([]*core.Pod, 0, len(pods))
 	for _, pod := range pods {
-		if allowedNamespace(pod.GetNamespace()) && pod.Status.Phase != core.PodSucceeded {
+		if reported(pod) && pod.Status.Phase != core.PodSucceeded {
 			if w.om != nil {
 				w.om(pod)
 			}
@@ -84,9 +104,10 @@ func (w *CoreWatchers) loadCmaps(ctx context.Context) []*core.ConfigMap {
 		return nil
 	}
 
+	reported := w.exclusions.Reported(ctx)
 	fcmaps := make([]*core.ConfigMap, 0, len(cmaps))
 	for _, cmap := range cmaps {
-		if allowedNamespace(cmap.GetNamespace()) {
+		if reported(cmap) {
 			if w.om != nil {
 				w.om(cmap)
 			}
@@ -104,9 +125,10 @@ func (w *CoreWatchers) loadDeploys(ctx context.Context) []*apps.Deployment {
 		return nil
 	}
 
+	reported := w.exclusions.Reported(ctx)
 	fdeploys := make([]*apps.Deployment, 0, len(deploys))
 	for _, deploy := range deploys {
-		if allowedNamespace(deploy.GetNamespace()) {
+		if reported(deploy) {
 			if w.om != nil {
 				w.om(deploy)
 			}
@@ -124,9 +146,10 @@ func (w *CoreWatchers) loadEndpoints(ctx context.Context) []*core.Endpoints {
 		return nil
 	}
 
+	reported := w.exclusions.Reported(ctx)
 	fendpts := make([]*core.Endpoints, 0, len(endpts))
 	for _, endpt := range endpts {
-		if allowedNamespace(endpt.GetNamespace()) {
+		if reported(endpt) {
 			if w.om != nil {
 				w.om(endpt)
 			}
@@ -137,13 +160,91 @@ func (w *CoreWatchers) loadEndpoints(ctx context.Context) []*core.Endpoints {
 	return fendpts
 }
 
-// allowedNamespace will check if resources from the given namespace
-// should be reported to Ambassador Cloud.
-func allowedNamespace(namespace string) bool {
-	return namespace != "kube-system"
+func (w *CoreWatchers) loadStatefulSets(ctx context.Context) []*apps.StatefulSet {
+	statefulSets, err := w.statefulSetWatchers.List(ctx)
+	if err != nil {
+		dlog.Errorf(ctx, "Unable to find statefulsets: %v", err)
+		return nil
+	}
+
+	reported := w.exclusions.Reported(ctx)
+	fstatefulSets := make([]*apps.StatefulSet, 0, len(statefulSets))
+	for _, statefulSet := range statefulSets {
+		if reported(statefulSet) {
+			if w.om != nil {
+				w.om(statefulSet)
+			}
+			fstatefulSets = append(fstatefulSets, statefulSet)
+		}
+	}
+
+	return fstatefulSets
 }
 
-func (w *CoreWatchers) LoadSnapshot(ctx context.Context, snapshot *snapshotTypes.Snapshot) {
+func (w *CoreWatchers) loadDaemonSets(ctx context.Context) []*apps.DaemonSet {
+	daemonSets, err := w.daemonSetWatchers.List(ctx)
+	if err != nil {
+		dlog.Errorf(ctx, "Unable to find daemonsets: %v", err)
+		return nil
+	}
+
+	reported := w.exclusions.Reported(ctx)
+	fdaemonSets := make([]*apps.DaemonSet, 0, len(daemonSets))
+	for _, daemonSet := range daemonSets {
+		if reported(daemonSet) {
+			if w.om != nil {
+				w.om(daemonSet)
+			}
+			fdaemonSets = append(fdaemonSets, daemonSet)
+		}
+	}
+
+	return fdaemonSets
+}
+
+func (w *CoreWatchers) loadJobs(ctx context.Context) []*batch.Job {
+	jobs, err := w.jobWatchers.List(ctx)
+	if err != nil {
+		dlog.Errorf(ctx, "Unable to find jobs: %v", err)
+		return nil
+	}
+
+	reported := w.exclusions.Reported(ctx)
+	fjobs := make([]*batch.Job, 0, len(jobs))
+	for _, job := range jobs {
+		if reported(job) {
+			if w.om != nil {
+				w.om(job)
+			}
+			fjobs = append(fjobs, job)
+		}
+	}
+
+	return fjobs
+}
+
+func (w *CoreWatchers) loadCronJobs(ctx context.Context) []*batch.CronJob {
+	cronJobs, err := w.cronJobWatchers.List(ctx)
+	if err != nil {
+		dlog.Errorf(ctx, "Unable to find cronjobs: %v", err)
+		return nil
+	}
+
+	reported := w.exclusions.Reported(ctx)
+	fcronJobs := make([]*batch.CronJob, 0, len(cronJobs))
+	for _, cronJob := range cronJobs {
+		if reported(cronJob) {
+			if w.om != nil {
+				w.om(cronJob)
+			}
+			fcronJobs = append(fcronJobs, cronJob)
+		}
+	}
+
+	return fcronJobs
+}
+
+func (w *CoreWatchers) LoadSnapshot(ctx context.Context, snapshot *Snapshot) {
 	k8sSnap := snapshot.Kubernetes
 	k8sSnap.Pods = w.loadPods(ctx)
 	dlog.Debugf(ctx, "Found %d pods", len(k8sSnap.Pods))
@@ -156,6 +257,18 @@ func (w *CoreWatchers) LoadSnapshot(ctx context.Context, snapshot *snapshotTypes
 
 	k8sSnap.Endpoints = w.loadEndpoints(ctx)
 	dlog.Debugf(ctx, "Found %d Endpoints", len(k8sSnap.Endpoints))
+
+	k8sSnap.StatefulSets = w.loadStatefulSets(ctx)
+	dlog.Debugf(ctx, "Found %d StatefulSets", len(k8sSnap.StatefulSets))
+
+	k8sSnap.DaemonSets = w.loadDaemonSets(ctx)
+	dlog.Debugf(ctx, "Found %d DaemonSets", len(k8sSnap.DaemonSets))
+
+	k8sSnap.Jobs = w.loadJobs(ctx)
+	dlog.Debugf(ctx, "Found %d Jobs", len(k8sSnap.Jobs))
+
+	k8sSnap.CronJobs = w.loadCronJobs(ctx)
+	dlog.Debugf(ctx, "Found %d CronJobs", len(k8sSnap.CronJobs))
 }
 
 func (w *CoreWatchers) Subscribe(ctx context.Context) <-chan struct{} {
@@ -167,6 +280,10 @@ func (w *CoreWatchers) EnsureStarted(ctx context.Context) {
 	w.deployWatchers.EnsureStarted(ctx, nil)
 	w.podWatchers.EnsureStarted(ctx, nil)
 	w.endpointWatchers.EnsureStarted(ctx, nil)
+	w.statefulSetWatchers.EnsureStarted(ctx, nil)
+	w.daemonSetWatchers.EnsureStarted(ctx, nil)
+	w.jobWatchers.EnsureStarted(ctx, nil)
+	w.cronJobWatchers.EnsureStarted(ctx, nil)
 }
 
 func (w *CoreWatchers) Cancel() {
@@ -174,4 +291,8 @@ func (w *CoreWatchers) Cancel() {
 	w.deployWatchers.Cancel()
 	w.podWatchers.Cancel()
 	w.endpointWatchers.Cancel()
+	w.statefulSetWatchers.Cancel()
+	w.daemonSetWatchers.Cancel()
+	w.jobWatchers.Cancel()
+	w.cronJobWatchers.Cancel()
 }
@@ -0,0 +1,187 @@
+package watchers
+
+import (
+	"context"
+	"sync"
+
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/datawire/k8sapi/pkg/k8sapi"
+)
+
+// Exclusions leaves out of the snapshot the resources that match a label selector,
+// and the resources of the namespaces that match a namespace label selector, on top
+// of the resources of kube-system, which are never reported.
+type Exclusions struct {
+	selector          labels.Selector
+	namespaceSelector labels.Selector
+
+	// namespaceWatchers watch the namespaces that match the namespace selector. There
+	// is one per namespace to watch, as namespaces are not namespaced and a
+	// WatcherGroup holds one watcher per namespace. A watcher that fails to list is
+	// replaced by a new one from newNamespaceWatchers, as the failed one would then
+	// list what it has, which may not be all the excluded namespaces.
+	mu                   sync.Mutex
+	namespaceWatchers    []*k8sapi.Watcher[*core.Namespace]
+	newNamespaceWatchers []func() *k8sapi.Watcher[*core.Namespace]
+	// cond is broadcast by the namespace watchers, and forwarded to the conds of the
+	// watchers whose resources are filtered, so that their subscribers see the
+	// changes of the excluded namespaces.
+	cond    *sync.Cond
+	forward []*sync.Cond
+}
+
+// NewExclusions returns the Exclusions of the given selectors, either of which may be
+// nil to exclude nothing. When namespaces are given, only those namespaces are watched
+// for the namespace selector.
+func NewExclusions(ctx context.Context, namespaces []string, selector, namespaceSelector labels.Selector) *Exclusions {
+	e := &Exclusions{selector: selector, namespaceSelector: namespaceSelector}
+	if namespaceSelector == nil {
+		return e
+	}
+
+	coreClient := k8sapi.GetK8sInterface(ctx).CoreV1().RESTClient()
+	e.cond = &sync.Cond{
+		L: &sync.Mutex{},
+	}
+	labelOpt := k8sapi.WithLabelSelector[*core.Namespace](namespaceSelector.String())
+	if len(namespaces) == 0 {
+		e.addNamespaceWatcher(coreClient, labelOpt)
+	}
+	for _, ns := range namespaces {
+		// only the watched namespaces, so that the agent may be restricted to them with resourceNames
+		e.addNamespaceWatcher(coreClient, labelOpt, k8sapi.WithFieldSelector[*core.Namespace]("metadata.name="+ns))
+	}
+	return e
+}
+
+// addNamespaceWatcher adds a watcher of the namespaces that match the options.
+func (e *Exclusions) addNamespaceWatcher(getter cache.Getter, opts ...k8sapi.WatcherOpt[*core.Namespace]) {
+	newNamespaceWatcher := func() *k8sapi.Watcher[*core.Namespace] {
+		return k8sapi.NewWatcher[*core.Namespace]("namespaces", getter, e.cond, opts...)
+	}
+	e.namespaceWatchers = append(e.namespaceWatchers, newNamespaceWatcher())
+	e.newNamespaceWatchers = append(e.newNamespaceWatchers, newNamespaceWatcher)
+}
+
+func (e *Exclusions) EnsureStarted(ctx context.Context) {
+	if e == nil || e.cond == nil {
+		return
+	}
+	e.mu.Lock()
+	for _, w := range e.namespaceWatchers {
+		_ = w.EnsureStarted(ctx, nil)
+	}
+	e.mu.Unlock()
+	go func() {
+		for range k8sapi.Subscribe(ctx, e.cond) {
+			for _, cond := range e.forward {
+				cond.Broadcast()
+			}
+		}
+	}()
+}
+
+// forwardTo makes the changes of the excluded namespaces broadcast on cond too. It
+// must be called before EnsureStarted.
+func (e *Exclusions) forwardTo(cond *sync.Cond) {
+	if e == nil {
+		return
+	}
+	e.forward = append(e.forward, cond)
+}
+
+func (e *Exclusions) Cancel() {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, w := range e.namespaceWatchers {
+		w.Cancel()
+	}
+}
+
+// Check returns an error when the excluded namespaces can't be found, in which case
+// nothing is reported.
+func (e *Exclusions) Check(ctx context.Context) error {
+	_, err := e.excludedNamespaces(ctx)
+	return err
+}
+
+// Reported returns the func that tells whether an object is reported, given the
+// namespaces that are excluded now. A nil Exclusions only leaves out kube-system.
+// As exclusions keep resources private, nothing is reported when the excluded
+// namespaces can't be found.
+func (e *Exclusions) Reported(ctx context.Context) func(obj metav1.Object) bool {
+	if e == nil {
+		return func(obj metav1.Object) bool {
+			return AllowedNamespace(obj.GetNamespace())
+		}
+	}
+
+	excludedNamespaces, err := e.excludedNamespaces(ctx)
+	if err != nil {
+		dlog.Errorf(ctx, "Unable to find the excluded namespaces, no resources are reported: %v", err)
+		return func(metav1.Object) bool {
+			return false
+		}
+	}
+	return e.reported(excludedNamespaces)
+}
+
+// excludedNamespaces returns the names of the namespaces that match the namespace selector.
+func (e *Exclusions) excludedNamespaces(ctx context.Context) (map[string]struct{}, error) {
+	excludedNamespaces := make(map[string]struct{})
+	if e == nil {
+		return excludedNamespaces, nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i, w := range e.namespaceWatchers {
+		namespaces, err := w.List(ctx)
+		if err != nil {
+			w.Cancel()
+			e.namespaceWatchers[i] = e.newNamespaceWatchers[i]()
+			return nil, err
+		}
+		for _, ns := range namespaces {
+			excludedNamespaces[ns.Name] = struct{}{}
+		}
+	}
+	return excludedNamespaces, nil
+}
+
+func (e *Exclusions) reported(excludedNamespaces map[string]struct{}) func(obj metav1.Object) bool {
+	return func(obj metav1.Object) bool {
+		if !AllowedNamespace(obj.GetNamespace()) {
+			return false
+		}
+		if _, ok := excludedNamespaces[obj.GetNamespace()]; ok {
+			return false
+		}
+		return e.selector == nil || !e.selector.Matches(labels.Set(obj.GetLabels()))
+	}
+}
+
+// Filter returns the objects that are reported.
+func Filter[T metav1.Object](ctx context.Context, e *Exclusions, objs []T) []T {
+	reported := e.Reported(ctx)
+	fobjs := make([]T, 0, len(objs))
+	for _, obj := range objs {
+		if reported(obj) {
+			fobjs = append(fobjs, obj)
+		}
+	}
+	return fobjs
+}
+
+// AllowedNamespace will check if resources from the given namespace
+// should be reported to Ambassador Cloud.
+func AllowedNamespace(namespace string) bool {
+	return namespace != "kube-system"
+}
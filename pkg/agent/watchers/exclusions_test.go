@@ -0,0 +1,112 @@
+package watchers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/datawire/k8sapi/pkg/k8sapi"
+)
+
+func newExclusionsPod(name, namespace string, labels map[string]string) *core.Pod {
+	return &core.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels}}
+}
+
+func TestExclusions_Reported(t *testing.T) {
+	selector, err := labels.Parse("ambassador-agent/ignore=true")
+	require.NoError(t, err)
+	e := &Exclusions{selector: selector}
+	reported := e.reported(map[string]struct{}{"sandbox": {}})
+
+	assert.True(t, reported(newExclusionsPod("app", "default", map[string]string{"app": "quote"})))
+	assert.True(t, reported(newExclusionsPod("app", "default", map[string]string{"ambassador-agent/ignore": "false"})))
+	assert.False(t, reported(newExclusionsPod("app", "default", map[string]string{"ambassador-agent/ignore": "true"})))
+	assert.False(t, reported(newExclusionsPod("app", "sandbox", nil)))
+	assert.False(t, reported(newExclusionsPod("coredns", "kube-system", nil)))
+}
+
+func TestFilter(t *testing.T) {
+	ctx := context.Background()
+	pods := []*core.Pod{
+		newExclusionsPod("app", "default", nil),
+		newExclusionsPod("coredns", "kube-system", nil),
+		newExclusionsPod("ignored", "default", map[string]string{"ambassador-agent/ignore": "true"}),
+	}
+
+	// without exclusions, only kube-system is left out
+	filtered := Filter(ctx, nil, pods)
+	require.Len(t, filtered, 2)
+	assert.Equal(t, "ignored", filtered[1].Name)
+
+	selector, err := labels.Parse("ambassador-agent/ignore=true")
+	require.NoError(t, err)
+	filtered = Filter(ctx, &Exclusions{selector: selector}, pods)
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "app", filtered[0].Name)
+}
+
+func TestExclusions_ReportedFailsClosed(t *testing.T) {
+	// the agent isn't allowed to list the namespaces
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Forbidden","code":403}`))
+	}))
+	defer srv.Close()
+	client, err := rest.RESTClientFor(&rest.Config{
+		Host:    srv.URL,
+		APIPath: "/api",
+		ContentConfig: rest.ContentConfig{
+			GroupVersion:         &core.SchemeGroupVersion,
+			NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		},
+	})
+	require.NoError(t, err)
+
+	e := &Exclusions{cond: &sync.Cond{L: &sync.Mutex{}}}
+	e.addNamespaceWatcher(client, k8sapi.WithLabelSelector[*core.Namespace]("ambassador-agent/ignore=true"))
+	failed := e.namespaceWatchers[0]
+
+	ctx, cancel := context.WithTimeout(dlog.NewTestContext(t, false), 200*time.Millisecond)
+	defer cancel()
+	reported := e.Reported(ctx)
+	assert.False(t, reported(newExclusionsPod("app", "default", nil)))
+
+	// the failed watcher is replaced, so that it fails again rather than list nothing
+	assert.NotSame(t, failed, e.namespaceWatchers[0])
+	ctx, cancel = context.WithTimeout(dlog.NewTestContext(t, false), 200*time.Millisecond)
+	defer cancel()
+	assert.Error(t, e.Check(ctx))
+}
+
+func TestExclusions_Forward(t *testing.T) {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	defer cancel()
+	e := &Exclusions{cond: &sync.Cond{L: &sync.Mutex{}}}
+	cond := &sync.Cond{L: &sync.Mutex{}}
+	e.forwardTo(cond)
+	e.EnsureStarted(ctx)
+
+	ch := k8sapi.Subscribe(ctx, cond)
+	assert.Eventually(t, func() bool {
+		e.cond.Broadcast()
+		select {
+		case <-ch:
+			return true
+		default:
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -16,10 +16,11 @@ type FallbackWatchers struct {
 	serviceWatchers k8sapi.WatcherGroup[*core.Service]
 	ingressWatchers ingressWatcher
 
-	om ObjectModifier
+	om         ObjectModifier
+	exclusions *Exclusions
 }
 
-func NewFallbackWatcher(ctx context.Context, namespaces []string, om ObjectModifier) *FallbackWatchers {
+func NewFallbackWatcher(ctx context.Context, namespaces []string, om ObjectModifier, exclusions *Exclusions) *FallbackWatchers {
 	coreClient := k8sapi.GetK8sInterface(ctx).CoreV1().RESTClient()
 
 	cond := &sync.Cond{
@@ -32,12 +33,15 @@ func NewFallbackWatcher(ctx context.Context, namespaces []string, om ObjectModif
 		namespaces = append(namespaces, "")
 	}
 
+	exclusions.forwardTo(cond)
+
 	// TODO equals func to prevent over-broadcasting
 	siWatcher := &FallbackWatchers{
 		serviceWatchers: k8sapi.NewWatcherGroup[*core.Service](),
 		ingressWatchers: getIngressWatcher(ctx, namespaces, cond, om),
 		cond:            cond,
 		om:              om,
+		exclusions:      exclusions,
 	}
 
 	for _, ns := range namespaces {
@@ -58,10 +62,12 @@ func (w *FallbackWatchers) Cancel() {
 	w.ingressWatchers.Cancel()
 }
 
-func (w *FallbackWatchers) LoadSnapshot(ctx context.Context, snapshot *snapshotTypes.Snapshot) {
-	var err error
-	if snapshot.Kubernetes.Services, err = w.serviceWatchers.List(ctx); err != nil {
+func (w *FallbackWatchers) LoadSnapshot(ctx context.Context, snapshot *Snapshot) {
+	if services, err := w.serviceWatchers.List(ctx); err != nil {
 		dlog.Errorf(ctx, "Unable to find services: %v", err)
+		snapshot.Kubernetes.Services = nil
+	} else {
+		snapshot.Kubernetes.Services = Filter(ctx, w.exclusions, services)
 	}
 	if w.om != nil {
 		for _, svc := range snapshot.Kubernetes.Services {
@@ -73,7 +79,7 @@ func (w *FallbackWatchers) LoadSnapshot(ctx context.Context, snapshot *snapshotT
 		dlog.Errorf(ctx, "Unable to find ingresses: %v", err)
 	} else {
 		snapshot.Kubernetes.Ingresses = []*snapshotTypes.Ingress{}
-		for _, ing := range ingresses {
+		for _, ing := range Filter(ctx, w.exclusions, ingresses) {
 			if w.om != nil {
 				w.om(ing)
 			}
@@ -0,0 +1,47 @@
+package watchers
+
+import (
+	apps "k8s.io/api/apps/v1"
+	batch "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	snapshotTypes "github.com/emissary-ingress/emissary/v3/pkg/snapshot/v1"
+)
+
+// Snapshot is the snapshot that the agent reports: the Emissary snapshot, with a
+// Kubernetes section that also holds the kinds of resources that Emissary doesn't
+// know of. It is encoded as the Emissary snapshot plus those extra fields.
+type Snapshot struct {
+	*snapshotTypes.Snapshot
+	Kubernetes *KubernetesSnapshot `json:"Kubernetes"`
+}
+
+// KubernetesSnapshot extends the Kubernetes section of the Emissary snapshot.
+type KubernetesSnapshot struct {
+	*snapshotTypes.KubernetesSnapshot
+
+	StatefulSets []*apps.StatefulSet `json:"StatefulSets,omitempty"`
+	DaemonSets   []*apps.DaemonSet   `json:"DaemonSets,omitempty"`
+	Jobs         []*batch.Job        `json:"Jobs,omitempty"`
+	CronJobs     []*batch.CronJob    `json:"CronJobs,omitempty"`
+
+	// Gateway API resources, in the version that the cluster serves. The Gateways and
+	// HTTPRoutes of the Emissary snapshot only hold the v1alpha1 ones that Emissary knows.
+	GatewayAPIGateways   []*unstructured.Unstructured `json:"gateways.gateway.networking.k8s.io,omitempty"`
+	GatewayAPIHTTPRoutes []*unstructured.Unstructured `json:"httproutes.gateway.networking.k8s.io,omitempty"`
+	GatewayAPIGRPCRoutes []*unstructured.Unstructured `json:"grpcroutes.gateway.networking.k8s.io,omitempty"`
+
+	// ExtraResources holds the resources that the agent is configured to watch, by
+	// resource.version.group, when the cluster serves them.
+	ExtraResources map[string][]*unstructured.Unstructured `json:"ExtraResources,omitempty"`
+}
+
+// NewSnapshot returns the extended snapshot of the given Emissary snapshot, which
+// shares its Kubernetes section.
+func NewSnapshot(snapshot *snapshotTypes.Snapshot) *Snapshot {
+	s := &Snapshot{Snapshot: snapshot}
+	if snapshot.Kubernetes != nil {
+		s.Kubernetes = &KubernetesSnapshot{KubernetesSnapshot: snapshot.Kubernetes}
+	}
+	return s
+}
@@ -26,10 +26,12 @@ const _ = grpc.SupportPackageIsVersion7
 const (
 	Director_Report_FullMethodName              = "/agent.Director/Report"
 	Director_ReportStream_FullMethodName        = "/agent.Director/ReportStream"
+	Director_ReportDeltaStream_FullMethodName   = "/agent.Director/ReportDeltaStream"
 	Director_StreamDiagnostics_FullMethodName   = "/agent.Director/StreamDiagnostics"
 	Director_StreamMetrics_FullMethodName       = "/agent.Director/StreamMetrics"
 	Director_Retrieve_FullMethodName            = "/agent.Director/Retrieve"
 	Director_ReportCommandResult_FullMethodName = "/agent.Director/ReportCommandResult"
+	Director_StreamLogs_FullMethodName          = "/agent.Director/StreamLogs"
 )
 
 // DirectorClient is the client API for Director service.
@@ -42,6 +44,9 @@ type DirectorClient interface {
 	Report(ctx context.Context, in *Snapshot, opts ...grpc.CallOption) (*SnapshotResponse, error)
 	// Report a consistent Snapshot of information to the DCP.
 	ReportStream(ctx context.Context, opts ...grpc.CallOption) (Director_ReportStreamClient, error)
+	// Report the changes to the cluster state since the last Snapshot or
+	// SnapshotDelta that the DCP accepted.
+	ReportDeltaStream(ctx context.Context, opts ...grpc.CallOption) (Director_ReportDeltaStreamClient, error)
 	// Report a consistent Diagnostics snapshot of information to the DCP.
 	StreamDiagnostics(ctx context.Context, opts ...grpc.CallOption) (Director_StreamDiagnosticsClient, error)
 	// Stream metrics to the DCP.
@@ -50,6 +55,9 @@ type DirectorClient interface {
 	Retrieve(ctx context.Context, in *Identity, opts ...grpc.CallOption) (Director_RetrieveClient, error)
 	// Reports the result of a command execution to the cloud
 	ReportCommandResult(ctx context.Context, in *CommandResult, opts ...grpc.CallOption) (*CommandResultResponse, error)
+	// Stream the output of a LogsCommand to the DCP. The result of the
+	// command is reported with ReportCommandResult once the stream is closed.
+	StreamLogs(ctx context.Context, opts ...grpc.CallOption) (Director_StreamLogsClient, error)
 }
 
 type directorClient struct {
@@ -104,8 +112,42 @@ func (x *directorReportStreamClient) CloseAndRecv() (*SnapshotResponse, error) {
 	return m, nil
 }
 
+func (c *directorClient) ReportDeltaStream(ctx context.Context, opts ...grpc.CallOption) (Director_ReportDeltaStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Director_ServiceDesc.Streams[1], Director_ReportDeltaStream_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &directorReportDeltaStreamClient{stream}
+	return x, nil
+}
+
+type Director_ReportDeltaStreamClient interface {
+	Send(*RawSnapshotDeltaChunk) error
+	CloseAndRecv() (*SnapshotResponse, error)
+	grpc.ClientStream
+}
+
+type directorReportDeltaStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *directorReportDeltaStreamClient) Send(m *RawSnapshotDeltaChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *directorReportDeltaStreamClient) CloseAndRecv() (*SnapshotResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(SnapshotResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *directorClient) StreamDiagnostics(ctx context.Context, opts ...grpc.CallOption) (Director_StreamDiagnosticsClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Director_ServiceDesc.Streams[1], Director_StreamDiagnostics_FullMethodName, opts...)
+	stream, err := c.cc.NewStream(ctx, &Director_ServiceDesc.Streams[2], Director_StreamDiagnostics_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -139,7 +181,7 @@ func (x *directorStreamDiagnosticsClient) CloseAndRecv() (*DiagnosticsResponse,
 }
 
 func (c *directorClient) StreamMetrics(ctx context.Context, opts ...grpc.CallOption) (Director_StreamMetricsClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Director_ServiceDesc.Streams[2], Director_StreamMetrics_FullMethodName, opts...)
+	stream, err := c.cc.NewStream(ctx, &Director_ServiceDesc.Streams[3], Director_StreamMetrics_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -173,7 +215,7 @@ func (x *directorStreamMetricsClient) CloseAndRecv() (*StreamMetricsResponse, er
 }
 
 func (c *directorClient) Retrieve(ctx context.Context, in *Identity, opts ...grpc.CallOption) (Director_RetrieveClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Director_ServiceDesc.Streams[3], Director_Retrieve_FullMethodName, opts...)
+	stream, err := c.cc.NewStream(ctx, &Director_ServiceDesc.Streams[4], Director_Retrieve_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -213,6 +255,40 @@ func (c *directorClient) ReportCommandResult(ctx context.Context, in *CommandRes
 	return out, nil
 }
 
+func (c *directorClient) StreamLogs(ctx context.Context, opts ...grpc.CallOption) (Director_StreamLogsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Director_ServiceDesc.Streams[5], Director_StreamLogs_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &directorStreamLogsClient{stream}
+	return x, nil
+}
+
+type Director_StreamLogsClient interface {
+	Send(*LogsChunk) error
+	CloseAndRecv() (*LogsResponse, error)
+	grpc.ClientStream
+}
+
+type directorStreamLogsClient struct {
+	grpc.ClientStream
+}
+
+func (x *directorStreamLogsClient) Send(m *LogsChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *directorStreamLogsClient) CloseAndRecv() (*LogsResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(LogsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // DirectorServer is the server API for Director service.
 // All implementations must embed UnimplementedDirectorServer
 // for forward compatibility
@@ -223,6 +299,9 @@ type DirectorServer interface {
 	Report(context.Context, *Snapshot) (*SnapshotResponse, error)
 	// Report a consistent Snapshot of information to the DCP.
 	ReportStream(Director_ReportStreamServer) error
+	// Report the changes to the cluster state since the last Snapshot or
+	// SnapshotDelta that the DCP accepted.
+	ReportDeltaStream(Director_ReportDeltaStreamServer) error
 	// Report a consistent Diagnostics snapshot of information to the DCP.
 	StreamDiagnostics(Director_StreamDiagnosticsServer) error
 	// Stream metrics to the DCP.
@@ -231,6 +310,9 @@ type DirectorServer interface {
 	Retrieve(*Identity, Director_RetrieveServer) error
 	// Reports the result of a command execution to the cloud
 	ReportCommandResult(context.Context, *CommandResult) (*CommandResultResponse, error)
+	// Stream the output of a LogsCommand to the DCP. The result of the
+	// command is reported with ReportCommandResult once the stream is closed.
+	StreamLogs(Director_StreamLogsServer) error
 	mustEmbedUnimplementedDirectorServer()
 }
 
@@ -244,6 +326,9 @@ func (UnimplementedDirectorServer) Report(context.Context, *Snapshot) (*Snapshot
 func (UnimplementedDirectorServer) ReportStream(Director_ReportStreamServer) error {
 	return status.Errorf(codes.Unimplemented, "method ReportStream not implemented")
 }
+func (UnimplementedDirectorServer) ReportDeltaStream(Director_ReportDeltaStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method ReportDeltaStream not implemented")
+}
 func (UnimplementedDirectorServer) StreamDiagnostics(Director_StreamDiagnosticsServer) error {
 	return status.Errorf(codes.Unimplemented, "method StreamDiagnostics not implemented")
 }
@@ -256,6 +341,9 @@ func (UnimplementedDirectorServer) Retrieve(*Identity, Director_RetrieveServer)
 func (UnimplementedDirectorServer) ReportCommandResult(context.Context, *CommandResult) (*CommandResultResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ReportCommandResult not implemented")
 }
+func (UnimplementedDirectorServer) StreamLogs(Director_StreamLogsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamLogs not implemented")
+}
 func (UnimplementedDirectorServer) mustEmbedUnimplementedDirectorServer() {}
 
 // UnsafeDirectorServer may be embedded to opt out of forward compatibility for this service.
@@ -313,6 +401,32 @@ func (x *directorReportStreamServer) Recv() (*RawSnapshotChunk, error) {
 	return m, nil
 }
 
+func _Director_ReportDeltaStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DirectorServer).ReportDeltaStream(&directorReportDeltaStreamServer{stream})
+}
+
+type Director_ReportDeltaStreamServer interface {
+	SendAndClose(*SnapshotResponse) error
+	Recv() (*RawSnapshotDeltaChunk, error)
+	grpc.ServerStream
+}
+
+type directorReportDeltaStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *directorReportDeltaStreamServer) SendAndClose(m *SnapshotResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *directorReportDeltaStreamServer) Recv() (*RawSnapshotDeltaChunk, error) {
+	m := new(RawSnapshotDeltaChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _Director_StreamDiagnostics_Handler(srv interface{}, stream grpc.ServerStream) error {
 	return srv.(DirectorServer).StreamDiagnostics(&directorStreamDiagnosticsServer{stream})
 }
@@ -404,6 +518,32 @@ func _Director_ReportCommandResult_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Director_StreamLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DirectorServer).StreamLogs(&directorStreamLogsServer{stream})
+}
+
+type Director_StreamLogsServer interface {
+	SendAndClose(*LogsResponse) error
+	Recv() (*LogsChunk, error)
+	grpc.ServerStream
+}
+
+type directorStreamLogsServer struct {
+	grpc.ServerStream
+}
+
+func (x *directorStreamLogsServer) SendAndClose(m *LogsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *directorStreamLogsServer) Recv() (*LogsChunk, error) {
+	m := new(LogsChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Director_ServiceDesc is the grpc.ServiceDesc for Director service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -426,6 +566,11 @@ var Director_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Director_ReportStream_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "ReportDeltaStream",
+			Handler:       _Director_ReportDeltaStream_Handler,
+			ClientStreams: true,
+		},
 		{
 			StreamName:    "StreamDiagnostics",
 			Handler:       _Director_StreamDiagnostics_Handler,
@@ -441,6 +586,11 @@ var Director_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Director_Retrieve_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "StreamLogs",
+			Handler:       _Director_StreamLogs_Handler,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "agent/director.proto",
 }